@@ -0,0 +1,35 @@
+package asset
+
+import "time"
+
+// birthdayBlockTimeMargin is subtracted from a wallet's birthday when searching
+// for the wallet's birthday block. Block timestamps are not strictly increasing
+// and may be up to 2 hours behind the timestamps of earlier blocks, so a margin
+// is used to avoid picking a birthday block that is after the birthday.
+const birthdayBlockTimeMargin = 2 * time.Hour
+
+// SearchBirthdayBlock uses a binary search over the main chain blocks from the
+// genesis block to the block at tipHeight to find the height of the last block
+// that was mined before the provided birthday. blockTime should return the
+// timestamp of the main chain block at the specified height.
+func SearchBirthdayBlock(birthday time.Time, tipHeight int32, blockTime func(height int32) (time.Time, error)) (int32, error) {
+	birthday = birthday.Add(-birthdayBlockTimeMargin)
+
+	var birthdayHeight int32
+	low, high := int32(0), tipHeight
+	for low <= high {
+		mid := low + (high-low)/2
+		midBlockTime, err := blockTime(mid)
+		if err != nil {
+			return 0, err
+		}
+		if midBlockTime.Before(birthday) {
+			birthdayHeight = mid
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return birthdayHeight, nil
+}
@@ -0,0 +1,31 @@
+package asset
+
+import (
+	"sort"
+	"time"
+)
+
+// BlockInfo identifies a block on the main chain.
+type BlockInfo struct {
+	Height    int32
+	Hash      string
+	Timestamp time.Time
+}
+
+// EstimatedNetworkTip estimates the height of the network's best block from
+// the best blocks reported by the connected peers. The median of the reported
+// heights is used so that a single peer cannot skew the estimate. Returns
+// false if no peers are connected.
+func (s *SPVPeerManager) EstimatedNetworkTip() (int32, bool) {
+	peers := s.cs.Peers()
+	if len(peers) == 0 {
+		return 0, false
+	}
+
+	heights := make([]int32, 0, len(peers))
+	for _, peer := range peers {
+		heights = append(heights, peer.LastBlock())
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights[len(heights)/2], true
+}
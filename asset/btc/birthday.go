@@ -0,0 +1,136 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// birthdayCheckInterval is how often the wallet's sync status is checked while
+// waiting to map the wallet's birthday to a block.
+const birthdayCheckInterval = 5 * time.Second
+
+// blockTime returns the timestamp of the main chain block at the specified
+// height.
+func (w *Wallet[_]) blockTime(height int32) (time.Time, error) {
+	chainService := w.chainService.Load()
+	hash, err := chainService.GetBlockHash(int64(height))
+	if err != nil {
+		return time.Time{}, err
+	}
+	header, err := chainService.GetBlockHeader(hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return header.Timestamp, nil
+}
+
+// mapBirthdayToBlock waits for the wallet to sync and then saves the height of
+// the birthday block located by the wallet backend, if the birthday is not
+// already mapped to a block.
+func (w *Wallet[_]) mapBirthdayToBlock(ctx context.Context) {
+	if _, mapped := w.BirthdayBlock(); mapped {
+		return
+	}
+
+	ticker := time.NewTicker(birthdayCheckInterval)
+	defer ticker.Stop()
+	for !w.IsSynced() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	var birthdayBlock waddrmgr.BlockStamp
+	err := walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		var err error
+		birthdayBlock, _, err = w.Manager.BirthdayBlock(dbtx.ReadBucket(wAddrMgrBkt))
+		return err
+	})
+	if err != nil {
+		w.log.Errorf("error reading wallet birthday block: %v", err)
+		return
+	}
+
+	if err = w.SaveBirthdayBlock(birthdayBlock.Height); err != nil {
+		w.log.Errorf("error saving wallet birthday block: %v", err)
+	}
+}
+
+// SetBirthday updates the wallet's birthday. If the new birthday is before the
+// current birthday, the wallet is rescanned from the new birthday block and
+// this method blocks until the rescan completes. See Rescan for details about
+// the rescan. The wallet must be synced if the birthday is moved earlier.
+func (w *Wallet[_]) SetBirthday(ctx context.Context, birthday time.Time, listener asset.RescanProgressListener) error {
+	if w.mainWallet == nil {
+		return asset.ErrWalletNotOpen
+	}
+
+	rescanRequired := birthday.Before(w.Birthday())
+	synced := w.IsSynced()
+	if rescanRequired && !synced {
+		return asset.ErrNotSynced
+	}
+
+	// The new birthday can only be mapped to a block if the wallet is synced.
+	var birthdayBlock *waddrmgr.BlockStamp
+	if synced {
+		chainService := w.chainService.Load()
+		bestBlock, err := chainService.BestBlock()
+		if err != nil {
+			return fmt.Errorf("error getting best block: %w", err)
+		}
+		height, err := asset.SearchBirthdayBlock(birthday, bestBlock.Height, w.blockTime)
+		if err != nil {
+			return fmt.Errorf("error locating birthday block: %w", err)
+		}
+		hash, err := chainService.GetBlockHash(int64(height))
+		if err != nil {
+			return fmt.Errorf("error getting block hash for height %d: %w", height, err)
+		}
+		header, err := chainService.GetBlockHeader(hash)
+		if err != nil {
+			return fmt.Errorf("error getting block header for height %d: %w", height, err)
+		}
+		birthdayBlock = &waddrmgr.BlockStamp{
+			Height:    height,
+			Hash:      *hash,
+			Timestamp: header.Timestamp,
+		}
+	}
+
+	err := walletdb.Update(w.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		ns := dbtx.ReadWriteBucket(wAddrMgrBkt)
+		if err := w.Manager.SetBirthday(ns, birthday); err != nil {
+			return err
+		}
+		if birthdayBlock == nil {
+			return nil
+		}
+		return w.Manager.SetBirthdayBlock(ns, *birthdayBlock, true)
+	})
+	if err != nil {
+		return fmt.Errorf("error updating wallet birthday: %w", err)
+	}
+
+	if err = w.SaveBirthday(birthday); err != nil {
+		return err
+	}
+	if birthdayBlock == nil {
+		return nil
+	}
+	if err = w.SaveBirthdayBlock(birthdayBlock.Height); err != nil {
+		return err
+	}
+
+	if rescanRequired {
+		return w.Rescan(ctx, birthdayBlock.Height, listener)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package btc
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// BestBlock returns the best block for which the neutrino chain service has
+// both the block header and the filter header.
+func (w *Wallet[_]) BestBlock() (*asset.BlockInfo, error) {
+	chainService := w.chainService.Load()
+	if chainService == nil {
+		return nil, fmt.Errorf("chain service not initialized")
+	}
+	bestBlock, err := chainService.BestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("error getting best block: %w", err)
+	}
+	return &asset.BlockInfo{
+		Height:    bestBlock.Height,
+		Hash:      bestBlock.Hash.String(),
+		Timestamp: bestBlock.Timestamp,
+	}, nil
+}
+
+// BlockHeaderByHeight returns the header of the main chain block at the
+// specified height from the neutrino header store.
+func (w *Wallet[_]) BlockHeaderByHeight(height int32) (*wire.BlockHeader, error) {
+	chainService := w.chainService.Load()
+	if chainService == nil {
+		return nil, fmt.Errorf("chain service not initialized")
+	}
+	hash, err := chainService.GetBlockHash(int64(height))
+	if err != nil {
+		return nil, fmt.Errorf("error getting block hash for height %d: %w", height, err)
+	}
+	return w.BlockHeaderByHash(hash)
+}
+
+// BlockHeaderByHash returns the header of the block with the specified hash
+// from the neutrino header store.
+func (w *Wallet[_]) BlockHeaderByHash(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	chainService := w.chainService.Load()
+	if chainService == nil {
+		return nil, fmt.Errorf("chain service not initialized")
+	}
+	header, err := chainService.GetBlockHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block header %s: %w", hash, err)
+	}
+	return header, nil
+}
+
+// EstimatedNetworkTip estimates the height of the network's best block from
+// the best blocks reported by the wallet's peers. Returns false if no peers
+// are connected. Sync must be active.
+func (w *Wallet[_]) EstimatedNetworkTip() (int32, bool, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return 0, false, asset.ErrNotSyncing
+	}
+	height, ok := peerManager.EstimatedNetworkTip()
+	return height, ok, nil
+}
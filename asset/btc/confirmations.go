@@ -0,0 +1,50 @@
+package btc
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// WaitForConfirmations blocks until the wallet transaction with the specified
+// hash has at least n confirmations or ctx is canceled. listener, if not nil,
+// is notified when the number of confirmations changes. The transaction need
+// not be known to the wallet yet. asset.ErrTxDoubleSpent is returned if the
+// transaction is removed from the wallet because a conflicting transaction was
+// mined, and asset.ErrTxReorgedOut if the transaction's block is disconnected.
+// Confirmations are only updated while the wallet is syncing.
+func (w *Wallet[_]) WaitForConfirmations(ctx context.Context, txHash *chainhash.Hash, n int32, listener asset.ConfirmationsListener) error {
+	if w.mainWallet == nil {
+		return asset.ErrWalletNotOpen
+	}
+	return asset.WaitForConfirmations(ctx, w, n, func() (int32, bool, error) {
+		return w.txConfirmations(txHash)
+	}, listener)
+}
+
+// txConfirmations returns the number of confirmations of the wallet
+// transaction with the specified hash and whether the transaction was found.
+func (w *Wallet[_]) txConfirmations(txHash *chainhash.Hash) (int32, bool, error) {
+	var details *wtxmgr.TxDetails
+	err := walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		var err error
+		details, err = w.TxStore.TxDetails(dbtx.ReadBucket(wTxMgrBkt), txHash)
+		return err
+	})
+	if err != nil || details == nil {
+		return 0, false, err
+	}
+	if details.Block.Height < 0 { // unmined
+		return 0, true, nil
+	}
+	confirmations := w.Manager.SyncedTo().Height - details.Block.Height + 1
+	if confirmations < 1 {
+		// The wallet's synced-to block is behind the transaction's block
+		// during a rescan.
+		confirmations = 1
+	}
+	return confirmations, true, nil
+}
@@ -0,0 +1,51 @@
+package btc
+
+import (
+	"context"
+	"time"
+
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// relayNotifications translates the wallet's transaction notifications into
+// events for the wallet's event subscribers until ctx is canceled.
+func (w *Wallet[_]) relayNotifications(ctx context.Context) {
+	client := w.NtfnServer.TransactionNotifications()
+	defer client.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-client.C:
+			if !ok {
+				return
+			}
+			w.publishTxNotifications(n)
+		}
+	}
+}
+
+func (w *Wallet[_]) publishTxNotifications(n *wallet.TransactionNotifications) {
+	for _, hash := range n.DetachedBlocks {
+		w.NotifyBlockDisconnected(hash.String())
+	}
+	for _, block := range n.AttachedBlocks {
+		minedTxHashes := make([]string, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			minedTxHashes = append(minedTxHashes, tx.Hash.String())
+		}
+		w.NotifyBlockConnected(asset.BlockInfo{
+			Height:    block.Height,
+			Hash:      block.Hash.String(),
+			Timestamp: time.Unix(block.Timestamp, 0),
+		}, minedTxHashes)
+	}
+	for _, tx := range n.UnminedTransactions {
+		w.NotifyTxReceived(tx.Hash.String())
+	}
+	for _, balance := range n.NewBalances {
+		w.NotifyBalanceChanged(balance.Account, int64(balance.TotalBalance))
+	}
+}
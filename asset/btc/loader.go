@@ -3,6 +3,7 @@ package btc
 import (
 	"context"
 	"fmt"
+	"net"
 	"path/filepath"
 	"time"
 
@@ -21,6 +22,26 @@ const (
 	neutrinoDBName = "neutrino.db"
 )
 
+// chainDataFiles are the files created in the wallet's data directory by the
+// neutrino chain service to store block headers and filter headers.
+var chainDataFiles = []string{
+	neutrinoDBName,
+	"block_headers.bin",
+	"reg_filter_headers.bin",
+}
+
+// savedPeersFileName is the file used by the SPV peer manager to persist peers
+// added by the user.
+const savedPeersFileName = "saved-peers.json"
+
+// backendFiles are the file name patterns of the files created in the wallet's
+// data directory by the wallet backend, the neutrino chain service, the SPV
+// peer manager and the log rotator, if logs are written to the data directory.
+var backendFiles = append(append(append([]string{
+	wallet.WalletDBName,
+	"peers.json",
+}, chainDataFiles...), asset.PeerStoreFiles(savedPeersFileName)...), asset.LogFiles(LogFileName)...)
+
 // WalletExistsAt checks if a wallet exists at the specified directory.
 func WalletExistsAt(dir string) (bool, error) {
 	// only the dir argument is needed to check wallet existence.
@@ -33,7 +54,7 @@ func WalletExistsAt(dir string) (bool, error) {
 // provided passphrase and can be revealed for backup later by providing the
 // passphrase.
 func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[Tx], recovery *asset.RecoveryCfg) (*Wallet[Tx], error) {
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params.OpenWalletParams)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain: %w", err)
 	}
@@ -57,7 +78,7 @@ func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[T
 		}
 	}
 
-	wb, err := asset.NewWalletBase(params.OpenWalletParams, seed, params.Pass, walletTraits)
+	wb, err := asset.NewWalletBase(params.OpenWalletParams, seed, params.Pass, params.Birthday, walletTraits)
 	if err != nil {
 		return nil, fmt.Errorf("NewWalletBase error: %v", err)
 	}
@@ -85,34 +106,38 @@ func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[T
 	}
 
 	// Create the chain service DB.
-	neutrinoDBPath := filepath.Join(params.DataDir, neutrinoDBName)
-	db, err := walletdb.Create(params.DbDriver, neutrinoDBPath, true, dbTimeout)
+	db, err := createChainServiceDB(params.DataDir, params.DbDriver)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create neutrino db at %q: %w", neutrinoDBPath, err)
+		return nil, err
 	}
 
-	chainService, err := initializeChainService(params.DataDir, db, *chainParams)
+	peerStore := wb.PeerStore(filepath.Join(params.DataDir, savedPeersFileName))
+	chainService, err := initializeChainService(params.DataDir, db, *chainParams, params.Proxy, nil, peerStore)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize neutrino ChainService: %w", err)
 	}
 
 	bailOnWallet = false
-	return &Wallet[Tx]{
-		WalletBase:   wb,
-		mainWallet:   btcw,
-		dir:          params.DataDir,
-		dbDriver:     params.DbDriver,
-		log:          params.Logger,
-		loader:       loader,
-		db:           db,
-		chainService: chainService,
-		chainClient:  chain.NewNeutrinoClient(chainParams, chainService),
-	}, nil
+	w := &Wallet[Tx]{
+		WalletBase:                wb,
+		mainWallet:                btcw,
+		dir:                       params.DataDir,
+		proxy:                     params.Proxy,
+		stopChainServiceOnSyncEnd: params.StopChainServiceOnSyncEnd,
+		seedNodes:                 signetSeedNodes(params.Signet),
+		dbDriver:                  params.DbDriver,
+		log:                       params.Logger,
+		loader:                    loader,
+		db:                        db,
+	}
+	w.chainService.Store(chainService)
+	w.chainClient.Store(chain.NewNeutrinoClient(chainParams, chainService))
+	return w, nil
 }
 
 // CreateWatchOnlyWallet creates and opens a watchonly SPV wallet.
 func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, params asset.CreateWalletParams[Tx]) (*Wallet[Tx], error) {
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params.OpenWalletParams)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain: %w", err)
 	}
@@ -123,7 +148,7 @@ func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, p
 		return nil, fmt.Errorf("wallet at %q already exists", params.DataDir)
 	}
 
-	wb, err := asset.NewWalletBase(params.OpenWalletParams, nil, nil, asset.WalletTraitWatchOnly)
+	wb, err := asset.NewWalletBase(params.OpenWalletParams, nil, nil, params.Birthday, asset.WalletTraitWatchOnly)
 	if err != nil {
 		return nil, fmt.Errorf("NewWalletBase error: %v", err)
 	}
@@ -145,28 +170,30 @@ func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, p
 	}()
 
 	// Create the chain service DB.
-	neutrinoDBPath := filepath.Join(params.DataDir, neutrinoDBName)
-	db, err := walletdb.Create(params.DbDriver, neutrinoDBPath, true, dbTimeout)
+	db, err := createChainServiceDB(params.DataDir, params.DbDriver)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create neutrino db at %q: %w", neutrinoDBPath, err)
+		return nil, err
 	}
 
 	bailOnWallet = false
 	return &Wallet[Tx]{
-		dir:        params.DataDir,
-		dbDriver:   params.DbDriver,
-		log:        params.Logger,
-		loader:     loader,
-		db:         db,
-		WalletBase: wb,
-		mainWallet: btcw,
+		dir:                       params.DataDir,
+		proxy:                     params.Proxy,
+		stopChainServiceOnSyncEnd: params.StopChainServiceOnSyncEnd,
+		seedNodes:                 signetSeedNodes(params.Signet),
+		dbDriver:                  params.DbDriver,
+		log:                       params.Logger,
+		loader:                    loader,
+		db:                        db,
+		WalletBase:                wb,
+		mainWallet:                btcw,
 	}, nil
 }
 
 // LoadWallet loads a previously created SPV wallet. The wallet must be opened
 // via its OpenWallet method before it can be used.
 func LoadWallet[Tx any](ctx context.Context, params asset.OpenWalletParams[Tx]) (*Wallet[Tx], error) {
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain params: %w", err)
 	}
@@ -190,35 +217,63 @@ func LoadWallet[Tx any](ctx context.Context, params asset.OpenWalletParams[Tx])
 		return nil, fmt.Errorf("unable to open neutrino db at %q: %w", neutrinoDBPath, err)
 	}
 
-	chainService, err := initializeChainService(params.DataDir, db, *chainParams)
+	peerStore := wb.PeerStore(filepath.Join(params.DataDir, savedPeersFileName))
+	chainService, err := initializeChainService(params.DataDir, db, *chainParams, params.Proxy, nil, peerStore)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize neutrino ChainService: %w", err)
 	}
 
-	return &Wallet[Tx]{
-		WalletBase:   wb,
-		dir:          params.DataDir,
-		dbDriver:     params.DbDriver,
-		log:          params.Logger,
-		loader:       loader,
-		db:           db,
-		chainService: chainService,
-		chainClient:  chain.NewNeutrinoClient(chainParams, chainService),
-	}, nil
+	w := &Wallet[Tx]{
+		WalletBase:                wb,
+		dir:                       params.DataDir,
+		proxy:                     params.Proxy,
+		stopChainServiceOnSyncEnd: params.StopChainServiceOnSyncEnd,
+		seedNodes:                 signetSeedNodes(params.Signet),
+		dbDriver:                  params.DbDriver,
+		log:                       params.Logger,
+		loader:                    loader,
+		db:                        db,
+	}
+	w.chainService.Store(chainService)
+	w.chainClient.Store(chain.NewNeutrinoClient(chainParams, chainService))
+	return w, nil
+}
+
+// createChainServiceDB creates or opens the neutrino chain service DB in
+// dataDir.
+func createChainServiceDB(dataDir, dbDriver string) (walletdb.DB, error) {
+	neutrinoDBPath := filepath.Join(dataDir, neutrinoDBName)
+	db, err := walletdb.Create(dbDriver, neutrinoDBPath, true, dbTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create neutrino db at %q: %w", neutrinoDBPath, err)
+	}
+	return db, nil
 }
 
-func initializeChainService(dataDir string, db walletdb.DB, chainParams chaincfg.Params) (*neutrino.ChainService, error) {
-	return neutrino.NewChainService(neutrino.Config{
+// initializeChainService creates a neutrino chain service. If connectPeers is
+// not empty, the chain service connects exclusively to those peers and peer
+// discovery is disabled. The chain service refuses to connect to the peers
+// banned in peerStore.
+func initializeChainService(dataDir string, db walletdb.DB, chainParams chaincfg.Params, proxy *asset.ProxyConfig, connectPeers []string, peerStore asset.PeerStore) (*neutrino.ChainService, error) {
+	cfg := neutrino.Config{
 		DataDir:       dataDir,
 		Database:      db,
 		ChainParams:   chainParams,
 		PersistToDisk: true, // keep cfilter headers on disk for efficient rescanning
-		// AddPeers:      addPeers,
-		// ConnectPeers:  connectPeers,
+		ConnectPeers:  connectPeers,
 		// WARNING: PublishTransaction currently uses the entire duration
 		// because if an external bug, but even if the bug is resolved, a typical
 		// inv/getdata round trip is ~4 seconds, so we set this so neutrino does
 		// not cancel queries too readily.
 		BroadcastTimeout: 6 * time.Second,
-	})
+	}
+	var dial func(addr net.Addr) (net.Conn, error)
+	if proxy != nil {
+		dial = func(addr net.Addr) (net.Conn, error) {
+			return proxy.Dial(context.Background(), "tcp", addr.String())
+		}
+		cfg.NameResolver = proxy.LookupIP
+	}
+	cfg.Dialer = asset.PeerBanDialer(peerStore, dial)
+	return neutrino.NewChainService(cfg)
 }
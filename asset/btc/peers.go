@@ -0,0 +1,94 @@
+package btc
+
+import (
+	"time"
+
+	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/lightninglabs/neutrino"
+)
+
+// btcPeer wraps *neutrino.ServerPeer in order to satisfy the SPVPeer interface.
+type btcPeer struct {
+	*neutrino.ServerPeer
+}
+
+// Stats returns details and statistics about the peer. neutrino does not
+// expose the peer's ban score.
+func (p *btcPeer) Stats() *asset.PeerStats {
+	return &asset.PeerStats{
+		UserAgent:       p.UserAgent(),
+		ProtocolVersion: p.ProtocolVersion(),
+		Services:        p.Services().String(),
+		Inbound:         p.Inbound(),
+		PingTime:        time.Duration(p.LastPingMicros()) * time.Microsecond,
+		BytesSent:       p.BytesSent(),
+		BytesReceived:   p.BytesReceived(),
+		ConnectedAt:     p.TimeConnected(),
+		StartingHeight:  p.StartingHeight(),
+		BestHeight:      p.LastBlock(),
+	}
+}
+
+// Peers returns the peers that the wallet is connected to, as well as the
+// peers added by the user that the wallet may not currently be connected to.
+// Returns asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) Peers() ([]*asset.WalletPeer, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return nil, asset.ErrNotSyncing
+	}
+	return peerManager.Peers()
+}
+
+// AddPeer connects the wallet to the peer at addr and persists the peer so
+// that it is connected to again the next time the wallet syncs. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) AddPeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.AddPeer(addr)
+}
+
+// RemovePeer disconnects the wallet from a peer previously added by the user
+// and removes it from the persisted peers. Returns asset.ErrNotSyncing if the
+// wallet is not syncing.
+func (w *Wallet[_]) RemovePeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.RemovePeer(addr)
+}
+
+// BanPeer disconnects the wallet from the peer at addr and prevents the wallet
+// from connecting to it again for the specified duration. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) BanPeer(addr string, duration time.Duration, reason string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.BanPeer(addr, duration, reason)
+}
+
+// UnbanPeer lifts a ban previously placed on the peer at addr. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) UnbanPeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.UnbanPeer(addr)
+}
+
+// BannedPeers returns the peers that are currently banned. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) BannedPeers() ([]*asset.BannedPeer, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return nil, asset.ErrNotSyncing
+	}
+	return peerManager.BannedPeers(), nil
+}
@@ -3,11 +3,19 @@ package btc
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/btcsuite/btcwallet/chain"
 	"github.com/itswisdomagain/libwallet/asset"
 	"github.com/lightninglabs/neutrino"
 )
 
+// syncStateCheckInterval is how often the progress of the chain service is
+// checked to update the wallet's sync state.
+const syncStateCheckInterval = 2 * time.Second
+
 // btcChainService wraps *neutrino.ChainService in order to translate the
 // neutrino.ServerPeer to the SPVPeer interface type.
 type btcChainService struct {
@@ -18,16 +26,21 @@ func (s *btcChainService) Peers() []asset.SPVPeer {
 	rawPeers := s.ChainService.Peers()
 	peers := make([]asset.SPVPeer, 0, len(rawPeers))
 	for _, p := range rawPeers {
-		peers = append(peers, p)
+		peers = append(peers, &btcPeer{p})
 	}
 	return peers
 }
 
 // StartSync connects the wallet to the blockchain network via SPV and returns
 // immediately. The wallet stays connected in the background until the provided
-// ctx is canceled or either StopSync or CloseWallet is called.
+// ctx is canceled or either StopSync or CloseWallet is called. mode determines
+// how the specified peers are used, see asset.PeerConnectionMode.
 // TODO: Accept sync ntfn listeners.
-func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers []string, savedPeersFilePath string) error {
+func (w *Wallet[Tx]) StartSync(ctx context.Context, mode asset.PeerConnectionMode, peers ...string) error {
+	if err := mode.ValidatePeers(peers); err != nil {
+		return err
+	}
+
 	// Initialize the ctx to use for sync. Will error if sync was already
 	// started.
 	ctx, err := w.InitializeSyncContext(ctx)
@@ -35,23 +48,88 @@ func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers []string, savedP
 		return err
 	}
 
-	w.log.Info("Starting sync...")
-	if err = w.chainClient.Start(); err != nil { // lazily starts connmgr
+	// Neutrino only disables peer discovery if it is created with a list of
+	// peers to connect to exclusively, so recreate the chain service if it
+	// was created for a different mode or was stopped when the last sync
+	// ended.
+	var connectPeers []string
+	if mode == asset.PeerModeConnectOnly {
+		connectPeers = make([]string, 0, len(peers))
+		for _, peer := range peers {
+			connectPeers = append(connectPeers, asset.WithDefaultPort(peer, w.ChainParams().DefaultPort))
+		}
+	}
+	if err = w.prepareChainService(connectPeers); err != nil {
+		w.SyncEnded(err)
+		return err
+	}
+
+	// A chain client cannot be restarted once stopped, so use a new one for
+	// every sync.
+	chainClient := chain.NewNeutrinoClient(w.ChainParams(), w.chainService.Load())
+	w.chainClient.Store(chainClient)
+
+	w.log.Infof("Starting sync in %s mode...", mode)
+	if err = chainClient.Start(); err != nil { // lazily starts connmgr
 		w.SyncEnded(err)
 		return fmt.Errorf("couldn't start Neutrino client: %v", err)
 	}
+	w.chainServiceStarted = true
+
+	w.SynchronizeRPC(chainClient)
+
+	// Chain client is started. Connect peers, including any signet seed nodes
+	// unless connecting exclusively to the specified peers.
+	defaultPeers := peers
+	if mode != asset.PeerModeConnectOnly {
+		defaultPeers = make([]string, 0, len(w.seedNodes)+len(peers))
+		defaultPeers = append(defaultPeers, w.seedNodes...)
+		defaultPeers = append(defaultPeers, peers...)
+	}
+	peerManager := w.NewSPVPeerManager(&btcChainService{w.chainService.Load()}, defaultPeers, filepath.Join(w.dir, savedPeersFileName), w.ChainParams().DefaultPort, w.proxy)
+	if mode == asset.PeerModeConnectOnly {
+		// The chain service connects to the specified peers on its own.
+		peerManager.AddDefaultPeers(false)
+	} else {
+		peerManager.ConnectToInitialWalletPeers()
+	}
+	w.peerManager.Store(peerManager)
+
+	// The sync goroutines are tracked so that sync only ends once they have
+	// all exited.
+	var wg sync.WaitGroup
+	goSync := func(f func(ctx context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(ctx)
+		}()
+	}
+
+	goSync(func(ctx context.Context) {
+		peerManager.ReresolvePeersPeriodically(ctx, asset.DefaultPeerReresolveInterval)
+	})
 
-	w.SynchronizeRPC(w.chainClient)
+	// Map the wallet's birthday to a block once the wallet is synced.
+	goSync(w.mapBirthdayToBlock)
 
-	// Chain client is started. Connect peers.
-	peerManager := asset.NewSPVPeerManager(&btcChainService{w.chainService}, connectPeers, savedPeersFilePath, w.log, w.ChainParams().DefaultPort)
-	peerManager.ConnectToInitialWalletPeers()
+	// Track the progress of the sync.
+	goSync(w.monitorSyncState)
 
-	// Start a goroutine to monitor when the sync ctx is canceled and then
-	// disconnect the sync.
+	// Relay block and transaction notifications to event subscribers.
+	goSync(w.relayNotifications)
+
+	// Match the watched scripts and outpoints.
+	w.startWatchRescan(ctx)
+
+	// Start a goroutine to supervise the sync, restarting it if it stalls,
+	// until the sync ctx is canceled and then disconnect the sync. Restarts
+	// and shutdown happen on the same goroutine so they cannot overlap.
 	go func() {
-		<-ctx.Done()
+		w.NewSyncSupervisor(&syncSupervisorBackend[Tx]{w}).Run(ctx)
 		w.log.Info("Stopping wallet synchronization")
+		wg.Wait()
+		w.peerManager.Store(nil)
 
 		// Stop the synchronization and notify that sync has ended via the
 		// SyncEnded() method. Stopping sync happens in 4 steps:
@@ -63,15 +141,15 @@ func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers []string, savedP
 
 		// 2. Stop the chain client.
 		w.log.Trace("Stopping neutrino chain client")
-		w.chainClient.Stop()
-		w.chainClient.WaitForShutdown()
+		chainClient := w.chainClient.Load()
+		chainClient.Stop()
+		chainClient.WaitForShutdown()
 
-		// 3. Stop the chain service.
-		// TODO: Don't stop chain service because it is difficult to restart later?
-		// w.log.Trace("Stopping neutrino chain sync service")
-		// if err := w.chainService.Stop(); err != nil {
-		// 	w.log.Errorf("error stopping neutrino chain service: %v", err)
-		// }
+		// 3. Stop the chain service if requested. Otherwise, it is kept
+		// running, with its peer connections, for the next sync.
+		if w.stopChainServiceOnSyncEnd {
+			w.stopChainService()
+		}
 
 		// 4. Restart the wallet. Ensures that wallet features not requiring
 		// sync can continue to work.
@@ -84,6 +162,35 @@ func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers []string, savedP
 	return nil
 }
 
+// monitorSyncState periodically updates the wallet's sync state from the
+// progress of the chain service until ctx is canceled.
+func (w *Wallet[_]) monitorSyncState(ctx context.Context) {
+	ticker := time.NewTicker(syncStateCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		chainService := w.chainService.Load()
+		switch {
+		case w.IsSynced():
+			w.SetSyncState(asset.SyncStateSynced)
+		case len(chainService.Peers()) == 0:
+			w.SetSyncState(asset.SyncStateConnecting)
+		case !chainService.IsCurrent():
+			w.SetSyncState(asset.SyncStateSyncingHeaders)
+		default:
+			// Headers are synced, the wallet is catching up by checking
+			// the filters of the remaining blocks.
+			w.SetSyncState(asset.SyncStateSyncingFilters)
+		}
+	}
+}
+
 // IsSyncing returns true if the wallet is catching up to the mainchain's best
 // block.
 func (w *Wallet[_]) IsSyncing() bool {
@@ -98,3 +205,118 @@ func (w *Wallet[_]) IsSyncing() bool {
 func (w *Wallet[_]) IsSynced() bool {
 	return w.ChainSynced()
 }
+
+// syncSupervisorBackend implements asset.SyncSupervisorBackend for a Wallet.
+type syncSupervisorBackend[Tx any] struct {
+	w *Wallet[Tx]
+}
+
+func (b *syncSupervisorBackend[_]) BestHeaderHeight() (int32, error) {
+	bestBlock, err := b.w.chainService.Load().BestBlock()
+	if err != nil {
+		return 0, err
+	}
+	return bestBlock.Height, nil
+}
+
+func (b *syncSupervisorBackend[_]) SyncedHeight() int32 {
+	return b.w.Manager.SyncedTo().Height
+}
+
+func (b *syncSupervisorBackend[_]) PeerCount() int {
+	return len(b.w.chainService.Load().Peers())
+}
+
+func (b *syncSupervisorBackend[_]) IsSynced() bool {
+	return b.w.IsSynced()
+}
+
+func (b *syncSupervisorBackend[_]) IsRescanning() bool {
+	return b.w.IsRescanning()
+}
+
+// RestartSync replaces the wallet's chain client with a new one and reconnects
+// to the peers tracked by the peer manager. The chain service is not restarted
+// because neutrino does not support restarting a stopped chain service.
+func (b *syncSupervisorBackend[_]) RestartSync(ctx context.Context) error {
+	w := b.w
+	w.log.Info("Restarting neutrino chain client")
+
+	// Stopping the wallet also stops the chain client and dissociates it from
+	// the wallet. A stopped chain client cannot be restarted.
+	w.mainWallet.Stop()
+	w.mainWallet.WaitForShutdown()
+	oldChainClient := w.chainClient.Load()
+	oldChainClient.Stop()
+	oldChainClient.WaitForShutdown()
+
+	chainClient := chain.NewNeutrinoClient(w.ChainParams(), w.chainService.Load())
+	w.chainClient.Store(chainClient)
+	err := chainClient.Start()
+	w.mainWallet.Start()
+	if err != nil {
+		return fmt.Errorf("couldn't start Neutrino client: %v", err)
+	}
+	if ctx.Err() != nil {
+		// Sync is being stopped, don't reconnect.
+		return ctx.Err()
+	}
+	w.SynchronizeRPC(chainClient)
+
+	w.SetSyncState(asset.SyncStateConnecting)
+	if peerManager := w.peerManager.Load(); peerManager != nil {
+		peerManager.ReconnectPeers()
+	}
+	return nil
+}
+
+// prepareChainService creates a new chain service if there is none, if the
+// existing one was stopped or if it was not created to connect exclusively to
+// connectPeers. An empty connectPeers enables peer discovery. Sync must not be
+// running when this is called.
+func (w *Wallet[_]) prepareChainService(connectPeers []string) error {
+	if w.chainService.Load() != nil && !w.chainServiceStopped && equalPeers(w.connectPeers, connectPeers) {
+		return nil
+	}
+
+	if w.chainService.Load() != nil && !w.chainServiceStopped {
+		w.log.Debug("Recreating neutrino chain service for new peer connection mode")
+		// The old chain service is marked stopped right away so that it is
+		// not reused or stopped again if creating the new one fails.
+		w.stopChainService()
+	}
+
+	// The neutrino db is closed if recreating the chain service failed after
+	// the chain data was reset.
+	if w.db == nil {
+		db, err := createChainServiceDB(w.dir, w.dbDriver)
+		if err != nil {
+			return err
+		}
+		w.db = db
+	}
+
+	peerStore := w.PeerStore(filepath.Join(w.dir, savedPeersFileName))
+	chainService, err := initializeChainService(w.dir, w.db, *w.ChainParams(), w.proxy, connectPeers, peerStore)
+	if err != nil {
+		return fmt.Errorf("unable to initialize neutrino ChainService: %w", err)
+	}
+
+	w.chainService.Store(chainService)
+	w.chainServiceStarted = false
+	w.chainServiceStopped = false
+	w.connectPeers = connectPeers
+	return nil
+}
+
+func equalPeers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
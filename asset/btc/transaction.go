@@ -0,0 +1,66 @@
+package btc
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/itswisdomagain/libwallet/walletdata"
+)
+
+// TransactionFromSummary converts a btcwallet transaction summary to a
+// walletdata.Transaction. blockHeight should be -1 for unmined transactions.
+func TransactionFromSummary(summary *wallet.TransactionSummary, blockHeight int32, chainParams *chaincfg.Params) (*walletdata.Transaction, error) {
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(summary.Transaction)); err != nil {
+		return nil, fmt.Errorf("error decoding tx %s: %w", summary.Hash, err)
+	}
+
+	txSummary := &walletdata.TxSummary{
+		Hash:        summary.Hash.String(),
+		BlockHeight: blockHeight,
+		Timestamp:   summary.Timestamp,
+		Type:        walletdata.TxTypeRegular,
+		Fee:         int64(summary.Fee),
+		Inputs:      make([]*walletdata.TxInput, len(msgTx.TxIn)),
+		Outputs:     make([]*walletdata.TxOutput, len(msgTx.TxOut)),
+		MyInputs:    make([]walletdata.MyTxInput, len(summary.MyInputs)),
+		MyOutputs:   make([]walletdata.MyTxOutput, len(summary.MyOutputs)),
+	}
+	if blockchain.IsCoinBaseTx(&msgTx) {
+		txSummary.Type = walletdata.TxTypeCoinbase
+	}
+	for i, txIn := range msgTx.TxIn {
+		txSummary.Inputs[i] = &walletdata.TxInput{PreviousOutpoint: txIn.PreviousOutPoint.String()}
+	}
+	for i, txOut := range msgTx.TxOut {
+		output := &walletdata.TxOutput{
+			Index:  uint32(i),
+			Amount: txOut.Value,
+		}
+		if _, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, chainParams); err == nil && len(addrs) == 1 {
+			output.Address = addrs[0].String()
+		}
+		txSummary.Outputs[i] = output
+	}
+	for i, in := range summary.MyInputs {
+		txSummary.MyInputs[i] = walletdata.MyTxInput{
+			Index:   in.Index,
+			Amount:  int64(in.PreviousAmount),
+			Account: in.PreviousAccount,
+		}
+	}
+	for i, out := range summary.MyOutputs {
+		txSummary.MyOutputs[i] = walletdata.MyTxOutput{
+			Index:    out.Index,
+			Account:  out.Account,
+			IsChange: out.Internal,
+		}
+	}
+
+	return txSummary.Transaction(), nil
+}
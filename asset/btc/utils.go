@@ -10,6 +10,8 @@ import (
 	"github.com/itswisdomagain/libwallet/asset"
 )
 
+// ParseChainParams returns the chain params for the specified network. The
+// default public signet params are returned for the Signet network.
 func ParseChainParams(net asset.Network) (*chaincfg.Params, error) {
 	switch net {
 	case asset.Mainnet:
@@ -18,8 +20,46 @@ func ParseChainParams(net asset.Network) (*chaincfg.Params, error) {
 		return &chaincfg.TestNet3Params, nil
 	case asset.Regtest:
 		return &chaincfg.RegressionNetParams, nil
+	case asset.Simnet:
+		return &chaincfg.SimNetParams, nil
+	case asset.Signet:
+		return &chaincfg.SigNetParams, nil
 	}
-	return nil, fmt.Errorf("unknown network ID %v", net)
+	return nil, fmt.Errorf("unknown network ID %d", uint8(net))
+}
+
+// parseWalletChainParams returns the chain params to use for a wallet created
+// or loaded with the provided params. The custom chain params registered with
+// params.ChainName are returned if a chain name is provided. Otherwise, this is
+// like ParseChainParams but returns the params for a custom signet if the
+// network is Signet and the provided signet config has a challenge script.
+func parseWalletChainParams[Tx any](params asset.OpenWalletParams[Tx]) (*chaincfg.Params, error) {
+	if params.ChainName != "" {
+		if params.Signet != nil {
+			return nil, fmt.Errorf("signet config cannot be used with custom chain params")
+		}
+		return customChainParams.Lookup(params.ChainName)
+	}
+	if params.Signet == nil {
+		return ParseChainParams(params.Net)
+	}
+	if params.Net != asset.Signet {
+		return nil, fmt.Errorf("signet config cannot be used with %s", params.Net)
+	}
+	if len(params.Signet.Challenge) == 0 {
+		return &chaincfg.SigNetParams, nil
+	}
+	signetParams := chaincfg.CustomSignetParams(params.Signet.Challenge, nil)
+	return &signetParams, nil
+}
+
+// signetSeedNodes returns the seed nodes in the provided signet config, if
+// any.
+func signetSeedNodes(signet *asset.SignetConfig) []string {
+	if signet == nil {
+		return nil
+	}
+	return signet.SeedNodes
 }
 
 func extendAddresses(extIdx, intIdx uint32, btcw *wallet.Wallet) error {
@@ -41,3 +81,29 @@ func extendAddresses(extIdx, intIdx uint32, btcw *wallet.Wallet) error {
 		return nil
 	})
 }
+
+// rewindToBirthday sets the wallet's synced-to block to its birthday block, or
+// to the block the wallet was created at if the birthday block is not yet
+// known. Returns the height of the block the wallet was rewound to.
+func rewindToBirthday(btcw *wallet.Wallet) (int32, error) {
+	var rewindHeight int32
+	err := walletdb.Update(btcw.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		ns := dbtx.ReadWriteBucket(wAddrMgrBkt)
+		birthdayBlock, _, err := btcw.Manager.BirthdayBlock(ns)
+		if waddrmgr.IsError(err, waddrmgr.ErrBirthdayBlockNotSet) {
+			// Passing a nil block stamp resets the synced-to block to the
+			// wallet's start block.
+			if err = btcw.Manager.SetSyncedTo(ns, nil); err != nil {
+				return err
+			}
+			rewindHeight = btcw.Manager.SyncedTo().Height
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rewindHeight = birthdayBlock.Height
+		return btcw.Manager.SetSyncedTo(ns, &birthdayBlock)
+	})
+	return rewindHeight, err
+}
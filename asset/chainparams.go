@@ -0,0 +1,86 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// CustomChainParams describes the chain params of a private test network. The
+// chain params of the specified base network are used for any property that
+// is not specified.
+type CustomChainParams struct {
+	// Name is the name used to select these chain params when creating or
+	// loading a wallet. It must not be the name of a built-in network.
+	Name string `json:"name"`
+	// BaseNetwork is the name of the built-in network, e.g. "regtest", whose
+	// chain params are used as the starting point for these params.
+	BaseNetwork string `json:"baseNetwork"`
+	// NetMagic is the magic number used to identify the network's messages.
+	NetMagic uint32 `json:"netMagic,omitempty"`
+	// DefaultPort is the default p2p port of the network's nodes.
+	DefaultPort string `json:"defaultPort,omitempty"`
+	// DNSSeeds are the DNS seeds used to discover the network's nodes.
+	DNSSeeds []string `json:"dnsSeeds,omitempty"`
+	// GenesisBlock is the hex-encoded serialized genesis block.
+	GenesisBlock string `json:"genesisBlock,omitempty"`
+}
+
+// ReadCustomChainParamsFile reads the JSON-encoded CustomChainParams in the
+// specified file.
+func ReadCustomChainParamsFile(filePath string) (*CustomChainParams, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	params := new(CustomChainParams)
+	if err = json.Unmarshal(content, params); err != nil {
+		return nil, fmt.Errorf("invalid chain params file %s: %w", filePath, err)
+	}
+	return params, nil
+}
+
+// ChainParamsRegistry holds custom chain params registered by name. The zero
+// value is ready to use.
+type ChainParamsRegistry[P any] struct {
+	mtx    sync.RWMutex
+	params map[string]P
+}
+
+// Register adds the provided chain params to the registry with the specified
+// name. It is an error to use the name of a built-in network or a name that is
+// already registered.
+func (r *ChainParamsRegistry[P]) Register(name string, params P) error {
+	if name == "" {
+		return fmt.Errorf("chain params name is required")
+	}
+	if _, err := NetFromString(name); err == nil {
+		return fmt.Errorf("%s is a built-in network", name)
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.params[name]; exists {
+		return fmt.Errorf("chain params for %s are already registered", name)
+	}
+	if r.params == nil {
+		r.params = make(map[string]P)
+	}
+	r.params[name] = params
+	return nil
+}
+
+// Lookup returns the chain params registered with the specified name.
+func (r *ChainParamsRegistry[P]) Lookup(name string) (P, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	params, found := r.params[name]
+	if !found {
+		return params, fmt.Errorf("no chain params registered for %s", name)
+	}
+	return params, nil
+}
@@ -0,0 +1,81 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// confirmationsCheckInterval is how often the confirmations of a transaction
+// are checked while waiting for confirmations, in addition to every time an
+// event is received.
+const confirmationsCheckInterval = 30 * time.Second
+
+// TxConfirmationsFunc returns the number of confirmations of a transaction and
+// whether the transaction is known to the wallet. Unmined transactions have 0
+// confirmations.
+type TxConfirmationsFunc func() (confirmations int32, found bool, err error)
+
+// ConfirmationsListener is notified of the confirmations of a transaction while
+// waiting for the transaction to be confirmed.
+type ConfirmationsListener func(confirmations int32)
+
+// EventSubscriber is implemented by wallets that emit events.
+type EventSubscriber interface {
+	SubscribeEvents(bufferSize int) *EventSubscription
+}
+
+// WaitForConfirmations blocks until txConfirmations reports at least n
+// confirmations for a transaction. The confirmations are checked whenever
+// subscriber emits an event and listener, if not nil, is notified when the
+// number of confirmations changes. The transaction need not be known to the
+// wallet when this is called. Returns ErrTxDoubleSpent if the transaction is
+// removed from the wallet after being seen, which happens when a conflicting
+// transaction is mined, and ErrTxReorgedOut if the transaction is returned to
+// the mempool after being mined.
+func WaitForConfirmations(ctx context.Context, subscriber EventSubscriber, n int32, txConfirmations TxConfirmationsFunc, listener ConfirmationsListener) error {
+	if n < 1 {
+		return fmt.Errorf("invalid number of confirmations: %d", n)
+	}
+
+	sub := subscriber.SubscribeEvents(0)
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(confirmationsCheckInterval)
+	defer ticker.Stop()
+
+	var seen, mined bool
+	lastConfirmations := int32(-1)
+	for {
+		confirmations, found, err := txConfirmations()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !found && seen:
+			return ErrTxDoubleSpent
+		case found && mined && confirmations == 0:
+			return ErrTxReorgedOut
+		case found:
+			seen = true
+			mined = confirmations > 0
+			if confirmations != lastConfirmations {
+				lastConfirmations = confirmations
+				if listener != nil {
+					listener(confirmations)
+				}
+			}
+			if confirmations >= n {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.Events():
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WalletDataDBFileName is the file name that a wallet's walletdata database
+// should have, if it is stored in the wallet's data directory, for the
+// database to be deleted along with the wallet's backend files.
+const WalletDataDBFileName = "walletdata.db"
+
+// LogFiles returns the file name patterns of the log file named logFileName
+// and of the files that it is rolled over to, for use as backend files of
+// wallets that write logs to their data directory.
+func LogFiles(logFileName string) []string {
+	return []string{logFileName, logFileName + ".*"}
+}
+
+// DeleteWalletFiles deletes the specified backend files from a wallet's data
+// directory. backendFiles may be file name patterns, as accepted by
+// filepath.Match. If keepWalletData is false, the walletdata database is also
+// deleted if it is stored in the data directory as WalletDataDBFileName. Other
+// files in the data directory are never deleted and the data directory is
+// only removed if it is empty.
+func DeleteWalletFiles(dataDir string, backendFiles []string, keepWalletData bool) error {
+	patterns := backendFiles
+	if !keepWalletData {
+		patterns = append(patterns[:len(patterns):len(patterns)], WalletDataDBFileName)
+	}
+	dirEntries, err := os.ReadDir(dataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading wallet data directory: %w", err)
+	}
+
+	var remaining int
+	for _, entry := range dirEntries {
+		fileName := entry.Name()
+		matched, err := matchesAny(fileName, patterns)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			remaining++
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(dataDir, fileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error deleting wallet file %s: %w", fileName, err)
+		}
+	}
+
+	// Only remove the data directory if nothing else, such as the walletdata
+	// database or files stored by the app, is left in it.
+	if remaining > 0 {
+		return nil
+	}
+	if err = os.Remove(dataDir); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing wallet data directory: %w", err)
+	}
+	return nil
+}
+
+// matchesAny returns true if fileName matches any of the file name patterns.
+func matchesAny(fileName string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, fileName)
+		if err != nil {
+			return false, fmt.Errorf("invalid wallet file pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
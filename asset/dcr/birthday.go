@@ -0,0 +1,114 @@
+package dcr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"decred.org/dcrwallet/v3/wallet/walletdb"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// mapBirthdayToBlock locates and saves the height of the last block mined
+// before the wallet's birthday, if the birthday has not already been mapped to
+// a block. The wallet's block headers should be synced before calling this
+// method. Returns 0 if the wallet's birthday is not known.
+func (w *Wallet[_]) mapBirthdayToBlock(ctx context.Context) (int32, error) {
+	birthday := w.Birthday()
+	if birthday.IsZero() {
+		return 0, nil
+	}
+	if height, mapped := w.BirthdayBlock(); mapped {
+		return height, nil
+	}
+
+	_, tipHeight := w.MainChainTip(ctx)
+	height, err := asset.SearchBirthdayBlock(birthday, tipHeight, func(height int32) (time.Time, error) {
+		blockInfo, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(blockInfo.Timestamp, 0), nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error locating birthday block: %w", err)
+	}
+
+	if err = w.SaveBirthdayBlock(height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// skipBlocksBeforeBirthday moves dcrwallet's rescan point to the wallet's
+// birthday block if the wallet has not processed any block since it was
+// created or restored, so that addresses are discovered and transactions are
+// rescanned from the birthday block rather than from the genesis block. The
+// wallet's block headers must be synced, and dcrwallet reads the rescan point
+// right after the headers are synced, so this is called when headers are
+// fetched. Wallets whose main chain was rolled back by ResetChainData are
+// still rescanned from the genesis block, since their transactions mined
+// before the birthday block would otherwise be left unmined.
+func (w *Wallet[_]) skipBlocksBeforeBirthday(ctx context.Context) error {
+	if w.Birthday().IsZero() {
+		return nil
+	}
+
+	db, txStore, err := w.txStore(ctx)
+	if err != nil {
+		return err
+	}
+	var processedNothing bool
+	err = walletdb.View(ctx, db, func(dbtx walletdb.ReadTx) error {
+		processedNothing = *txStore.ProcessedTxsBlockMarker(dbtx) == w.chainParams.GenesisHash
+		return nil
+	})
+	if err != nil || !processedNothing {
+		return err
+	}
+
+	birthdayHeight, err := w.mapBirthdayToBlock(ctx)
+	if err != nil || birthdayHeight <= 1 {
+		return err
+	}
+	// The rescan point is the block after the last processed block.
+	lastSkipped, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(birthdayHeight-1))
+	if err != nil {
+		return fmt.Errorf("error reading block %d: %w", birthdayHeight-1, err)
+	}
+	w.log.Infof("Skipping blocks before birthday block %d", birthdayHeight)
+	return walletdb.Update(ctx, db, func(dbtx walletdb.ReadWriteTx) error {
+		return txStore.UpdateProcessedTxsBlockMarker(dbtx, &lastSkipped.Hash)
+	})
+}
+
+// SetBirthday updates the wallet's birthday. If the new birthday is before the
+// current birthday, the wallet is rescanned from the new birthday block and
+// this method blocks until the rescan completes. See Rescan for details about
+// the rescan. The wallet must be synced if the birthday is moved earlier.
+func (w *Wallet[_]) SetBirthday(ctx context.Context, birthday time.Time, listener asset.RescanProgressListener) error {
+	rescanRequired := birthday.Before(w.Birthday())
+	synced := w.IsSynced()
+	if rescanRequired && !synced {
+		return asset.ErrNotSynced
+	}
+
+	if err := w.SaveBirthday(birthday); err != nil {
+		return err
+	}
+	if !synced {
+		// The birthday will be mapped to a block when the wallet is synced.
+		return nil
+	}
+
+	birthdayHeight, err := w.mapBirthdayToBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	if rescanRequired {
+		return w.Rescan(ctx, birthdayHeight, listener)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package dcr
+
+import (
+	"context"
+	"fmt"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// BestBlock returns the tip of the main chain recorded by the wallet.
+func (w *Wallet[_]) BestBlock(ctx context.Context) (*asset.BlockInfo, error) {
+	hash, height := w.MainChainTip(ctx)
+	header, err := w.BlockHeader(ctx, &hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block header %s: %w", hash, err)
+	}
+	return &asset.BlockInfo{
+		Height:    height,
+		Hash:      hash.String(),
+		Timestamp: header.Timestamp,
+	}, nil
+}
+
+// BlockHeaderByHeight returns the header of the main chain block at the
+// specified height from the wallet's header store.
+func (w *Wallet[_]) BlockHeaderByHeight(ctx context.Context, height int32) (*wire.BlockHeader, error) {
+	blockInfo, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(height))
+	if err != nil {
+		return nil, fmt.Errorf("error getting block at height %d: %w", height, err)
+	}
+	header := new(wire.BlockHeader)
+	if err = header.FromBytes(blockInfo.Header); err != nil {
+		return nil, fmt.Errorf("error decoding block header at height %d: %w", height, err)
+	}
+	return header, nil
+}
+
+// BlockHeaderByHash returns the header of the block with the specified hash
+// from the wallet's header store.
+func (w *Wallet[_]) BlockHeaderByHash(ctx context.Context, hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	header, err := w.BlockHeader(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block header %s: %w", hash, err)
+	}
+	return header, nil
+}
+
+// EstimatedNetworkTip estimates the height of the network's best block from
+// the best blocks reported by the wallet's peers. dcr peers only report their
+// best block when they connect, so the estimate is never lower than the
+// wallet's own tip. Returns false if no peers are connected. Sync must be
+// active.
+func (w *Wallet[_]) EstimatedNetworkTip(ctx context.Context) (int32, bool, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return 0, false, asset.ErrNotSyncing
+	}
+	height, ok := peerManager.EstimatedNetworkTip()
+	if !ok {
+		return 0, false, nil
+	}
+	if _, tipHeight := w.MainChainTip(ctx); tipHeight > height {
+		height = tipHeight
+	}
+	return height, true, nil
+}
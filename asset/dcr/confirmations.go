@@ -0,0 +1,29 @@
+package dcr
+
+import (
+	"context"
+
+	"decred.org/dcrwallet/v3/errors"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// WaitForConfirmations blocks until the wallet transaction with the specified
+// hash has at least n confirmations or ctx is canceled. listener, if not nil,
+// is notified when the number of confirmations changes. The transaction need
+// not be known to the wallet yet. asset.ErrTxDoubleSpent is returned if the
+// transaction is removed from the wallet because a conflicting transaction was
+// mined, and asset.ErrTxReorgedOut if the transaction's block is disconnected.
+// Confirmations are only updated while the wallet is syncing.
+func (w *Wallet[_]) WaitForConfirmations(ctx context.Context, txHash *chainhash.Hash, n int32, listener asset.ConfirmationsListener) error {
+	return asset.WaitForConfirmations(ctx, w, n, func() (int32, bool, error) {
+		_, confirmations, _, err := w.TransactionSummary(ctx, txHash)
+		if errors.Is(err, errors.NotExist) {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return confirmations, true, nil
+	}, listener)
+}
@@ -0,0 +1,50 @@
+package dcr
+
+import (
+	"context"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// relayNotifications translates the wallet's transaction notifications into
+// events for the wallet's event subscribers until ctx is canceled.
+func (w *Wallet[_]) relayNotifications(ctx context.Context) {
+	client := w.NtfnServer.TransactionNotifications()
+	defer client.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-client.C:
+			if !ok {
+				return
+			}
+			w.publishTxNotifications(n)
+		}
+	}
+}
+
+func (w *Wallet[_]) publishTxNotifications(n *wallet.TransactionNotifications) {
+	for _, header := range n.DetachedBlocks {
+		w.NotifyBlockDisconnected(header.BlockHash().String())
+	}
+	for _, block := range n.AttachedBlocks {
+		minedTxHashes := make([]string, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			minedTxHashes = append(minedTxHashes, tx.Hash.String())
+		}
+		w.NotifyBlockConnected(asset.BlockInfo{
+			Height:    int32(block.Header.Height),
+			Hash:      block.Header.BlockHash().String(),
+			Timestamp: block.Header.Timestamp,
+		}, minedTxHashes)
+	}
+	for _, tx := range n.UnminedTransactions {
+		w.NotifyTxReceived(tx.Hash.String())
+	}
+	for _, balance := range n.NewBalances {
+		w.NotifyBalanceChanged(balance.Account, int64(balance.TotalBalance))
+	}
+}
@@ -17,6 +17,23 @@ const (
 	walletDbName = "wallet.db"
 )
 
+// addrmgrPeersFileName is the file used by the SPV address manager to persist
+// known peer addresses.
+const addrmgrPeersFileName = "peers.json"
+
+// savedPeersFileName is the file used by the SPV peer manager to persist peers
+// added by the user.
+const savedPeersFileName = "saved-peers.json"
+
+// backendFiles are the file name patterns of the files created in the wallet's
+// data directory by the wallet backend, the SPV address manager, the SPV peer
+// manager and the log rotator, if logs are written to the data directory.
+var backendFiles = append(append([]string{
+	walletDbName,
+	addrmgrPeersFileName,
+	addrmgrPeersFileName + ".new",
+}, asset.PeerStoreFiles(savedPeersFileName)...), asset.LogFiles(LogFileName)...)
+
 // WalletExistsAt returns whether a wallet database file exists at the specified
 // directory. This may return an error for unexpected I/O failures.
 func WalletExistsAt(dataDir string) (bool, error) {
@@ -26,9 +43,10 @@ func WalletExistsAt(dataDir string) (bool, error) {
 // CreateWallet creates and opens an SPV wallet. If recovery params is not
 // provided, a new seed is generated and used. The seed is encrypted with the
 // provided passphrase and can be revealed for backup later by providing the
-// passphrase.
+// passphrase. The first sync discovers the wallet's addresses and transactions
+// from the block mined before params.Birthday, if a birthday is provided.
 func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[Tx], recovery *asset.RecoveryCfg) (*Wallet[Tx], error) {
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params.OpenWalletParams)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain params: %w", err)
 	}
@@ -56,7 +74,7 @@ func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[T
 		}
 	}
 
-	wb, err := asset.NewWalletBase(params.OpenWalletParams, seed, params.Pass, walletTraits)
+	wb, err := asset.NewWalletBase(params.OpenWalletParams, seed, params.Pass, params.Birthday, walletTraits)
 	if err != nil {
 		return nil, fmt.Errorf("NewWalletBase error: %v", err)
 	}
@@ -115,6 +133,7 @@ func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[T
 	return &Wallet[Tx]{
 		WalletBase:  wb,
 		dir:         params.DataDir,
+		proxy:       params.Proxy,
 		dbDriver:    params.DbDriver,
 		chainParams: chainParams,
 		log:         params.Logger,
@@ -125,7 +144,7 @@ func CreateWallet[Tx any](ctx context.Context, params asset.CreateWalletParams[T
 
 // CreateWatchOnlyWallet creates and opens a watchonly SPV wallet.
 func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, params asset.CreateWalletParams[Tx]) (*Wallet[Tx], error) {
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params.OpenWalletParams)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain params: %w", err)
 	}
@@ -141,7 +160,7 @@ func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, p
 		return nil, fmt.Errorf("check new wallet data directory error: %w", err)
 	}
 
-	wb, err := asset.NewWalletBase(params.OpenWalletParams, nil, nil, asset.WalletTraitWatchOnly)
+	wb, err := asset.NewWalletBase(params.OpenWalletParams, nil, nil, params.Birthday, asset.WalletTraitWatchOnly)
 	if err != nil {
 		return nil, fmt.Errorf("NewWalletBase error: %v", err)
 	}
@@ -187,6 +206,7 @@ func CreateWatchOnlyWallet[Tx any](ctx context.Context, extendedPubKey string, p
 	return &Wallet[Tx]{
 		WalletBase:  wb,
 		dir:         params.DataDir,
+		proxy:       params.Proxy,
 		dbDriver:    params.DbDriver,
 		chainParams: chainParams,
 		log:         params.Logger,
@@ -204,7 +224,7 @@ func LoadWallet[Tx any](ctx context.Context, params asset.OpenWalletParams[Tx])
 		return nil, fmt.Errorf("wallet at %q doesn't exist", params.DataDir)
 	}
 
-	chainParams, err := ParseChainParams(params.Net)
+	chainParams, err := parseWalletChainParams(params)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing chain params: %w", err)
 	}
@@ -217,6 +237,7 @@ func LoadWallet[Tx any](ctx context.Context, params asset.OpenWalletParams[Tx])
 	return &Wallet[Tx]{
 		WalletBase:  wb,
 		dir:         params.DataDir,
+		proxy:       params.Proxy,
 		dbDriver:    params.DbDriver,
 		chainParams: chainParams,
 		log:         params.Logger,
@@ -0,0 +1,337 @@
+package dcr
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"decred.org/dcrwallet/v3/errors"
+	"decred.org/dcrwallet/v3/p2p"
+	"decred.org/dcrwallet/v3/spv"
+	"github.com/decred/dcrd/addrmgr/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/slog"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// errPeerRemoved is the reason given to a remote peer that is disconnected
+// because it was removed by the user.
+var errPeerRemoved = errors.New("peer removed")
+
+// peerMonitorInterval is the interval at which the syncer's peers are checked
+// for peers that were disconnected due to misbehaviour.
+const peerMonitorInterval = 5 * time.Second
+
+// dcrPeer wraps *p2p.RemotePeer in order to satisfy the SPVPeer interface.
+type dcrPeer struct {
+	*p2p.RemotePeer
+}
+
+func (p *dcrPeer) StartingHeight() int32 {
+	return p.InitialHeight()
+}
+
+// LastBlock returns the height advertised by the peer when the connection was
+// established. p2p.RemotePeer does not track the peer's latest height.
+func (p *dcrPeer) LastBlock() int32 {
+	return p.InitialHeight()
+}
+
+func (p *dcrPeer) Addr() string {
+	return p.RemoteAddr().String()
+}
+
+// Stats returns details and statistics about the peer. p2p.RemotePeer does not
+// track ping times, traffic or connection times, and all peers connected by
+// the syncer are outbound.
+func (p *dcrPeer) Stats() *asset.PeerStats {
+	return &asset.PeerStats{
+		UserAgent:       p.UA(),
+		ProtocolVersion: p.Pver(),
+		Services:        p.Services().String(),
+		StartingHeight:  p.InitialHeight(),
+		BestHeight:      p.InitialHeight(),
+		BanScore:        p.BanScore(),
+	}
+}
+
+// syncerRestartDelay is how long peer changes are collected before the syncer
+// is restarted to apply them, so that changes made together, such as the
+// address change of a peer, restart the syncer once.
+const syncerRestartDelay = time.Second
+
+// dcrChainService adapts the dcr spv.Syncer to the PeerManagerChainService
+// interface. The syncer does not discover peers while it has persistent peers,
+// so peers are only made persistent in asset.PeerModeConnectOnly mode, and
+// peer changes are applied by restarting the syncer, which only reads its
+// persistent peers when it is started. In the other modes, peers are added to
+// the syncer's address manager as preferred candidates, without restarting the
+// syncer.
+type dcrChainService struct {
+	connectOnly bool
+	peerStore   asset.PeerStore
+	log         slog.Logger
+
+	mtx           sync.Mutex
+	syncer        *spv.Syncer
+	amgr          *addrmgr.AddrManager
+	peers         []string
+	restartSyncer func()
+	restartTimer  *time.Timer
+
+	// reportMisbehavior is called with the address of peers that the syncer
+	// disconnected due to a protocol violation, such as sending invalid
+	// headers or filters.
+	reportMisbehavior func(addr, reason string)
+}
+
+// newDCRChainService creates a dcrChainService for syncing in the specified
+// peer connection mode. Peers banned in peerStore are not added to the address
+// manager.
+func newDCRChainService(mode asset.PeerConnectionMode, peerStore asset.PeerStore, log slog.Logger) *dcrChainService {
+	return &dcrChainService{
+		connectOnly: mode == asset.PeerModeConnectOnly,
+		peerStore:   peerStore,
+		log:         log,
+	}
+}
+
+// setSyncer sets the syncer that is currently running, its address manager and
+// the function used to restart it. Returns the persistent peers that the
+// syncer should connect to, which are only set in asset.PeerModeConnectOnly
+// mode. In the other modes, the peers are added to amgr.
+func (s *dcrChainService) setSyncer(syncer *spv.Syncer, amgr *addrmgr.AddrManager, restart func()) []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.syncer = syncer
+	s.amgr = amgr
+	s.restartSyncer = restart
+	if s.restartTimer != nil {
+		s.restartTimer.Stop()
+		s.restartTimer = nil
+	}
+	if s.connectOnly {
+		return append([]string(nil), s.peers...)
+	}
+	if amgr != nil {
+		addPeersToAddrManager(amgr, s.peerStore, s.log, s.peers)
+	}
+	return nil
+}
+
+// scheduleRestart restarts the syncer after syncerRestartDelay to apply peer
+// changes, unless a restart is already scheduled. The mtx must be held.
+func (s *dcrChainService) scheduleRestart() {
+	if s.restartSyncer != nil && s.restartTimer == nil {
+		s.restartTimer = time.AfterFunc(syncerRestartDelay, s.restartSyncer)
+	}
+}
+
+// ConnectNode connects to the peer at addr. In asset.PeerModeConnectOnly mode,
+// addr is added to the syncer's persistent peers and the syncer is restarted.
+// Otherwise, addr is added to the address manager as a preferred candidate,
+// so that the syncer keeps connecting to discovered peers too.
+func (s *dcrChainService) ConnectNode(addr string, _ bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var known bool
+	for _, peer := range s.peers {
+		if peer == addr {
+			known = true
+			break
+		}
+	}
+	if !known {
+		s.peers = append(s.peers, addr)
+	}
+
+	if s.connectOnly {
+		if !known {
+			s.scheduleRestart()
+		}
+		return nil
+	}
+	if s.amgr != nil {
+		addPeersToAddrManager(s.amgr, s.peerStore, s.log, []string{addr})
+	}
+	return nil
+}
+
+// RemoveNodeByAddr disconnects the peer at addr if it is connected. In
+// asset.PeerModeConnectOnly mode, addr is also removed from the syncer's
+// persistent peers and the syncer is restarted.
+func (s *dcrChainService) RemoveNodeByAddr(addr string) error {
+	s.mtx.Lock()
+	var removed bool
+	for i, peer := range s.peers {
+		if peer == addr {
+			s.peers = append(s.peers[:i], s.peers[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	if removed && s.connectOnly {
+		s.scheduleRestart()
+	}
+	syncer := s.syncer
+	s.mtx.Unlock()
+
+	if syncer != nil {
+		for _, rp := range syncer.GetRemotePeers() {
+			if rp.RemoteAddr().String() == addr {
+				rp.Disconnect(errPeerRemoved)
+			}
+		}
+	}
+	return nil
+}
+
+// addPeersToAddrManager adds peers to the address manager and marks them as
+// good, so that they are preferred when the syncer selects peers to connect to.
+// The peers must be resolved ip:port or onion addresses, as resolved by the
+// peer manager, so that the address manager does not look them up itself.
+// Peers that are banned in peerStore are not added.
+func addPeersToAddrManager(amgr *addrmgr.AddrManager, peerStore asset.PeerStore, log slog.Logger, peers []string) {
+	// Start the address manager so that the addresses added below are not
+	// lost when the syncer starts it and loads the saved addresses.
+	amgr.Start()
+
+	for _, peer := range peers {
+		host, strPort, err := net.SplitHostPort(peer)
+		if err != nil {
+			log.Errorf("invalid peer address %s: %v", peer, err)
+			continue
+		}
+		port, err := strconv.ParseUint(strPort, 10, 16)
+		if err != nil {
+			log.Errorf("invalid port for peer %s: %v", peer, err)
+			continue
+		}
+		na, err := amgr.HostToNetAddress(host, uint16(port), wire.SFNodeNetwork)
+		if err != nil {
+			log.Errorf("failed to resolve peer %s: %v", peer, err)
+			continue
+		}
+		if err := asset.CheckPeerBan(peerStore, na.Key()); err != nil {
+			log.Infof("Not adding peer %s: %v", peer, err)
+			continue
+		}
+		amgr.AddAddresses([]*addrmgr.NetAddress{na}, na)
+		if err := amgr.Good(na); err != nil {
+			log.Errorf("failed to mark peer %s as good: %v", peer, err)
+		}
+	}
+}
+
+// monitorPeers watches the peers of the provided syncer until ctx is canceled
+// and reports the peers that were disconnected due to a protocol violation.
+func (s *dcrChainService) monitorPeers(ctx context.Context, syncer *spv.Syncer) {
+	knownPeers := make(map[string]*p2p.RemotePeer)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(peerMonitorInterval):
+		}
+
+		currentPeers := syncer.GetRemotePeers()
+		for k, rp := range knownPeers {
+			if _, connected := currentPeers[k]; connected {
+				continue
+			}
+			delete(knownPeers, k)
+			if err := rp.Err(); errors.Is(err, errors.Protocol) && s.reportMisbehavior != nil {
+				s.reportMisbehavior(rp.RemoteAddr().String(), err.Error())
+			}
+		}
+		for k, rp := range currentPeers {
+			knownPeers[k] = rp
+		}
+	}
+}
+
+// Peers returns the remote peers that the syncer is connected to.
+func (s *dcrChainService) Peers() []asset.SPVPeer {
+	s.mtx.Lock()
+	syncer := s.syncer
+	s.mtx.Unlock()
+
+	if syncer == nil {
+		return nil
+	}
+
+	rawPeers := syncer.GetRemotePeers()
+	peers := make([]asset.SPVPeer, 0, len(rawPeers))
+	for _, rp := range rawPeers {
+		peers = append(peers, &dcrPeer{rp})
+	}
+	return peers
+}
+
+// Peers returns the peers that the wallet is connected to, as well as the
+// peers added by the user that the wallet may not currently be connected to.
+// Returns asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) Peers() ([]*asset.WalletPeer, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return nil, asset.ErrNotSyncing
+	}
+	return peerManager.Peers()
+}
+
+// AddPeer connects the wallet to the peer at addr and persists the peer so
+// that it is connected to again the next time the wallet syncs. In
+// asset.PeerModeConnectOnly mode, sync is restarted to connect to the peer.
+// Returns asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) AddPeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.AddPeer(addr)
+}
+
+// RemovePeer disconnects the wallet from a peer previously added by the user
+// and removes it from the persisted peers. Returns asset.ErrNotSyncing if the
+// wallet is not syncing.
+func (w *Wallet[_]) RemovePeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.RemovePeer(addr)
+}
+
+// BanPeer disconnects the wallet from the peer at addr and prevents the wallet
+// from connecting to it again for the specified duration. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) BanPeer(addr string, duration time.Duration, reason string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.BanPeer(addr, duration, reason)
+}
+
+// UnbanPeer lifts a ban previously placed on the peer at addr. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) UnbanPeer(addr string) error {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return asset.ErrNotSyncing
+	}
+	return peerManager.UnbanPeer(addr)
+}
+
+// BannedPeers returns the peers that are currently banned. Returns
+// asset.ErrNotSyncing if the wallet is not syncing.
+func (w *Wallet[_]) BannedPeers() ([]*asset.BannedPeer, error) {
+	peerManager := w.peerManager.Load()
+	if peerManager == nil {
+		return nil, asset.ErrNotSyncing
+	}
+	return peerManager.BannedPeers(), nil
+}
@@ -0,0 +1,118 @@
+package dcr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/addrmgr/v2"
+	"github.com/decred/slog"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// TestChainServicePeerModes checks that the peers connected to in each peer
+// connection mode are only made persistent peers of the syncer, which disables
+// peer discovery, in asset.PeerModeConnectOnly mode, and that adding peers in
+// the other modes does not restart the syncer. The specified peers must be
+// resolved with the peer manager's resolver.
+func TestChainServicePeerModes(t *testing.T) {
+	const (
+		specifiedHost = "node.example"
+		specifiedPeer = "1.2.3.4:9108"
+		savedPeer     = "5.6.7.8:9108"
+		addedPeer     = "9.10.11.12:9108"
+	)
+
+	tests := []struct {
+		mode            asset.PeerConnectionMode
+		peers           []string
+		persistentPeers []string
+		addrMgrPeers    []string
+	}{{
+		mode:         asset.PeerModeDiscover,
+		addrMgrPeers: []string{savedPeer, addedPeer},
+	}, {
+		mode:         asset.PeerModeAddNodes,
+		peers:        []string{specifiedHost},
+		addrMgrPeers: []string{specifiedPeer, savedPeer, addedPeer},
+	}, {
+		mode:            asset.PeerModeConnectOnly,
+		peers:           []string{specifiedHost},
+		persistentPeers: []string{specifiedPeer},
+	}}
+
+	resolver := asset.ResolverFunc(func(_ context.Context, host string) ([]net.IP, error) {
+		if host != specifiedHost {
+			return nil, fmt.Errorf("unknown host %s", host)
+		}
+		return []net.IP{net.ParseIP("1.2.3.4")}, nil
+	})
+
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			store := asset.NewFilePeerStore(filepath.Join(dir, savedPeersFileName), slog.Disabled)
+			err := store.UpdateSavedPeers(func(peers map[string]asset.PeerSource) {
+				peers[savedPeer] = asset.AddedPeer
+			})
+			if err != nil {
+				t.Fatalf("error saving peer: %v", err)
+			}
+
+			cs := newDCRChainService(test.mode, store, slog.Disabled)
+			peerManager := asset.NewSPVPeerManager(cs, test.peers, store, slog.Disabled, "9108", nil)
+			peerManager.SetResolver(resolver, 0, false)
+			addInitialPeers(peerManager, test.mode)
+
+			amgr := addrmgr.New(dir, func(host string) ([]net.IP, error) {
+				return nil, fmt.Errorf("address manager looked up %s", host)
+			})
+			defer amgr.Stop()
+			restarted := make(chan struct{}, 1)
+			persistentPeers := cs.setSyncer(nil, amgr, func() { restarted <- struct{}{} })
+			if len(persistentPeers) != len(test.persistentPeers) {
+				t.Fatalf("expected persistent peers %v, got %v", test.persistentPeers, persistentPeers)
+			}
+			for i, peer := range test.persistentPeers {
+				if persistentPeers[i] != peer {
+					t.Fatalf("expected persistent peers %v, got %v", test.persistentPeers, persistentPeers)
+				}
+			}
+
+			if err := peerManager.AddPeer(addedPeer); err != nil {
+				t.Fatalf("error adding peer: %v", err)
+			}
+
+			// Attempt fails for addresses that the address manager does not
+			// know.
+			for _, peer := range []string{specifiedPeer, savedPeer, addedPeer} {
+				var expected bool
+				for _, p := range test.addrMgrPeers {
+					expected = expected || p == peer
+				}
+				host, _, _ := net.SplitHostPort(peer)
+				na, err := amgr.HostToNetAddress(host, 9108, 0)
+				if err != nil {
+					t.Fatalf("error parsing peer address: %v", err)
+				}
+				if known := amgr.Attempt(na) == nil; known != expected {
+					t.Fatalf("expected %s in address manager to be %v, got %v", peer, expected, known)
+				}
+			}
+
+			select {
+			case <-restarted:
+				if test.mode != asset.PeerModeConnectOnly {
+					t.Fatal("syncer restarted after adding a peer")
+				}
+			case <-time.After(2 * syncerRestartDelay):
+				if test.mode == asset.PeerModeConnectOnly {
+					t.Fatal("syncer not restarted after adding a peer")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package dcr
+
+import (
+	"context"
+	"fmt"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// Rescan rescans the blockchain for the wallet's transactions, starting from
+// the specified block height. This method blocks until the rescan completes,
+// the provided ctx is canceled, CancelRescan is called or sync is stopped.
+// Rescan progress is reported to the provided listener, if it is not nil. The
+// wallet must be synced before a rescan can be started.
+func (w *Wallet[_]) Rescan(ctx context.Context, fromHeight int32, listener asset.RescanProgressListener) error {
+	if !w.IsSynced() {
+		return asset.ErrNotSynced
+	}
+
+	ctx, err := w.InitializeRescanContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer w.RescanEnded()
+
+	syncer := w.syncer.Load()
+	if syncer == nil {
+		return asset.ErrNotSyncing
+	}
+	_, bestHeight := w.MainChainTip(ctx)
+
+	w.log.Infof("Rescanning from block %d", fromHeight)
+	progressCh := make(chan wallet.RescanProgress, 1)
+	go w.RescanProgressFromHeight(ctx, syncer, fromHeight, progressCh)
+
+	for p := range progressCh {
+		if p.Err != nil {
+			return fmt.Errorf("rescan error: %w", p.Err)
+		}
+		if listener != nil {
+			listener(&asset.RescanProgress{
+				StartHeight:    fromHeight,
+				ScannedThrough: p.ScannedThrough,
+				EndHeight:      bestHeight,
+			})
+		}
+	}
+
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	w.log.Info("Rescan complete")
+	return nil
+}
+
+// RescanFromBirthday rescans the blockchain for the wallet's transactions,
+// starting from the wallet's birthday block. The rescan starts from the genesis
+// block if the wallet's birthday is not known. See Rescan for more details.
+func (w *Wallet[_]) RescanFromBirthday(ctx context.Context, listener asset.RescanProgressListener) error {
+	if !w.IsSynced() {
+		return asset.ErrNotSynced
+	}
+
+	birthdayHeight, err := w.mapBirthdayToBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	return w.Rescan(ctx, birthdayHeight, listener)
+}
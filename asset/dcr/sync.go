@@ -3,18 +3,30 @@ package dcr
 import (
 	"context"
 	"net"
+	"path/filepath"
 	"time"
 
 	"decred.org/dcrwallet/v3/p2p"
 	"decred.org/dcrwallet/v3/spv"
 	"github.com/decred/dcrd/addrmgr/v2"
+	"github.com/itswisdomagain/libwallet/asset"
 )
 
 // StartSync connects the wallet to the blockchain network via SPV and returns
 // immediately. The wallet stays connected in the background until the provided
-// ctx is canceled or either StopSync or CloseWallet is called.
+// ctx is canceled or either StopSync or CloseWallet is called. mode determines
+// how the specified peers are used, see asset.PeerConnectionMode.
+//
+// The dcr syncer does not connect to discovered peers while it has persistent
+// peers, so outside asset.PeerModeConnectOnly mode the specified, saved and
+// added peers are added to the address manager as preferred candidates rather
+// than as persistent peers.
 // TODO: Accept sync ntfn listeners.
-func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers ...string) error {
+func (w *Wallet[_]) StartSync(ctx context.Context, mode asset.PeerConnectionMode, peers ...string) error {
+	if err := mode.ValidatePeers(peers); err != nil {
+		return err
+	}
+
 	// Initialize the ctx to use for sync. Will error if sync was already
 	// started.
 	ctx, err := w.InitializeSyncContext(ctx)
@@ -22,35 +34,88 @@ func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers ...string) error
 		return err
 	}
 
-	w.log.Info("Starting sync...")
+	w.log.Infof("Starting sync in %s mode...", mode)
 
-	addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0}
-	amgr := addrmgr.New(w.dir, net.LookupIP)
-	lp := p2p.NewLocalPeer(w.ChainParams(), addr, amgr)
-	syncer := spv.NewSyncer(w.mainWallet, lp)
-	if len(connectPeers) > 0 {
-		syncer.SetPersistentPeers(connectPeers)
+	// The address manager cannot be restarted once stopped, so a new address
+	// manager and local peer are created every time the syncer is started.
+	// Peers and seeders are dialed through the proxy, if any, and banned
+	// peers are never dialed, including peers found by the address manager.
+	lookupIP := net.LookupIP
+	var dial p2p.DialFunc
+	if w.proxy != nil {
+		lookupIP = w.proxy.LookupIP
+		dial = w.proxy.Dial
+	}
+	savedPeersFilePath := filepath.Join(w.dir, savedPeersFileName)
+	peerStore := w.PeerStore(savedPeersFilePath)
+	newLocalPeer := func() *p2p.LocalPeer {
+		addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0}
+		amgr := addrmgr.New(w.dir, lookupIP)
+		lp := p2p.NewLocalPeer(w.ChainParams(), addr, amgr)
+		lp.SetDialFunc(asset.PeerBanContextDialer(peerStore, dial))
+		return lp
 	}
 
-	w.syncer = syncer
-	w.SetNetworkBackend(syncer)
+	// Load the initial peers before starting the syncer so that they are
+	// connected to on the first run.
+	chainService := newDCRChainService(mode, peerStore, w.log)
+	peerManager := w.NewSPVPeerManager(chainService, peers, savedPeersFilePath, w.ChainParams().DefaultPort, w.proxy)
+	addInitialPeers(peerManager, mode)
+	chainService.reportMisbehavior = peerManager.ReportMisbehavingPeer
+	w.peerManager.Store(peerManager)
+	go peerManager.ReresolvePeersPeriodically(ctx, asset.DefaultPeerReresolveInterval)
+
+	// Relay block and transaction notifications to event subscribers.
+	go w.relayNotifications(ctx)
+
+	// Match the watched scripts and outpoints.
+	go w.scanWatchList(ctx)
 
 	// Start the syncer in a goroutine, monitor when the sync ctx is canceled
 	// and then disconnect the sync.
 	go func() {
+		endSync := func() {
+			chainService.setSyncer(nil, nil, nil)
+			w.peerManager.Store(nil)
+			w.syncer.Store(nil)
+			w.SetNetworkBackend(nil)
+			w.SyncEnded(nil)
+		}
+
 		for {
-			err := syncer.Run(ctx)
+			runCtx, cancelRun := context.WithCancel(ctx)
+			lp := newLocalPeer()
+			syncer := w.newSyncer(ctx, lp)
+			persistentPeers := chainService.setSyncer(syncer, lp.AddrManager(), cancelRun)
+			if len(persistentPeers) > 0 {
+				syncer.SetPersistentPeers(persistentPeers)
+			}
+			w.syncer.Store(syncer)
+			w.SetNetworkBackend(syncer)
+			go chainService.monitorPeers(runCtx, syncer)
+
+			err := syncer.Run(runCtx)
+			restarted := runCtx.Err() != nil
+			cancelRun()
 			if ctx.Err() != nil {
 				// sync ctx canceled, quit syncing
-				w.syncer = nil
-				w.SetNetworkBackend(nil)
-				w.SyncEnded(nil)
+				endSync()
 				return
 			}
 
+			if restarted {
+				// The syncer was stopped to apply peer changes, restart it
+				// immediately.
+				w.log.Info("Restarting SPV synchronization with updated peers")
+				continue
+			}
+
 			w.log.Errorf("SPV synchronization ended. Trying again in 10 seconds: %v", err)
+			w.SetSyncError(err)
+			w.SetSyncState(asset.SyncStateConnecting)
 			select {
 			case <-ctx.Done():
+				endSync()
 				return
 			case <-time.After(time.Second * 10):
 			}
@@ -60,6 +125,60 @@ func (w *Wallet[_]) StartSync(ctx context.Context, connectPeers ...string) error
 	return nil
 }
 
+// addInitialPeers adds the peers that the wallet connects to when sync starts
+// in the specified mode to peerManager, which resolves them with its resolver
+// before they are passed to the chain service. The peers specified for the
+// sync are the peer manager's default peers, and peers added by the user are
+// not connected to in asset.PeerModeConnectOnly mode.
+func addInitialPeers(peerManager *asset.SPVPeerManager, mode asset.PeerConnectionMode) {
+	if mode == asset.PeerModeConnectOnly {
+		peerManager.AddDefaultPeers(true)
+		return
+	}
+	peerManager.ConnectToInitialWalletPeers()
+}
+
+// newSyncer creates a syncer for the main wallet that reports the progress of
+// the sync and maps the wallet's birthday to a block once the wallet is synced.
+func (w *Wallet[_]) newSyncer(ctx context.Context, lp *p2p.LocalPeer) *spv.Syncer {
+	syncer := spv.NewSyncer(w.mainWallet, lp)
+	syncer.SetNotifications(&spv.Notifications{
+		PeerDisconnected: func(peerCount int32, addr string) {
+			if peerCount == 0 {
+				w.SetSyncState(asset.SyncStateConnecting)
+			}
+		},
+		FetchHeadersStarted: func() {
+			w.SetSyncState(asset.SyncStateSyncingHeaders)
+		},
+		FetchHeadersFinished: func() {
+			if err := w.skipBlocksBeforeBirthday(ctx); err != nil {
+				w.log.Errorf("error skipping blocks before wallet birthday: %v", err)
+			}
+		},
+		FetchMissingCFiltersStarted: func() {
+			w.SetSyncState(asset.SyncStateSyncingFilters)
+		},
+		RescanStarted: func() {
+			w.SetSyncState(asset.SyncStateRescanning)
+		},
+		Synced: func(synced bool) {
+			if !synced {
+				w.SetSyncState(asset.SyncStateSyncingHeaders)
+				return
+			}
+			w.SetSyncState(asset.SyncStateSynced)
+			w.signalWatchScan()
+			// Map the wallet's birthday to a block now that the block
+			// headers are synced.
+			if _, err := w.mapBirthdayToBlock(ctx); err != nil {
+				w.log.Errorf("error mapping wallet birthday to a block: %v", err)
+			}
+		},
+	})
+	return syncer
+}
+
 // IsSyncing returns true if the wallet is catching up to the mainchain's best
 // block.
 func (w *Wallet[_]) IsSyncing() bool {
@@ -72,8 +191,8 @@ func (w *Wallet[_]) IsSyncing() bool {
 // IsSynced returns true if the wallet has synced up to the best block on the
 // mainchain.
 func (w *Wallet[_]) IsSynced() bool {
-	if w.syncer != nil {
-		return w.syncer.Synced()
+	if syncer := w.syncer.Load(); syncer != nil {
+		return syncer.Synced()
 	}
 	return false
 }
@@ -0,0 +1,106 @@
+package dcr
+
+import (
+	"bytes"
+	"fmt"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/itswisdomagain/libwallet/walletdata"
+)
+
+// mixDenominations are the output amounts created by dcrwallet's CoinShuffle++
+// mixing.
+var mixDenominations = map[int64]struct{}{
+	1 << 36: {}, 1 << 34: {}, 1 << 32: {}, 1 << 30: {}, 1 << 28: {},
+	1 << 26: {}, 1 << 24: {}, 1 << 22: {}, 1 << 20: {}, 1 << 18: {},
+}
+
+// TransactionFromSummary converts a dcrwallet transaction summary to a
+// walletdata.Transaction. blockHeight should be -1 for unmined transactions.
+func TransactionFromSummary(summary *wallet.TransactionSummary, blockHeight int32, chainParams *chaincfg.Params) (*walletdata.Transaction, error) {
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(summary.Transaction)); err != nil {
+		return nil, fmt.Errorf("error decoding tx %s: %w", summary.Hash, err)
+	}
+
+	txSummary := &walletdata.TxSummary{
+		Hash:        summary.Hash.String(),
+		BlockHeight: blockHeight,
+		Timestamp:   summary.Timestamp,
+		Fee:         int64(summary.Fee),
+		Inputs:      make([]*walletdata.TxInput, len(msgTx.TxIn)),
+		Outputs:     make([]*walletdata.TxOutput, len(msgTx.TxOut)),
+		MyInputs:    make([]walletdata.MyTxInput, len(summary.MyInputs)),
+		MyOutputs:   make([]walletdata.MyTxOutput, len(summary.MyOutputs)),
+	}
+	switch summary.Type {
+	case wallet.TransactionTypeCoinbase:
+		txSummary.Type = walletdata.TxTypeCoinbase
+	case wallet.TransactionTypeTicketPurchase:
+		txSummary.Type = walletdata.TxTypeTicketPurchase
+	case wallet.TransactionTypeVote:
+		txSummary.Type = walletdata.TxTypeVote
+	case wallet.TransactionTypeRevocation:
+		txSummary.Type = walletdata.TxTypeRevocation
+	default:
+		txSummary.Type = walletdata.TxTypeRegular
+		if isMixTx(&msgTx) {
+			txSummary.Type = walletdata.TxTypeMix
+		}
+	}
+	for i, txIn := range msgTx.TxIn {
+		txSummary.Inputs[i] = &walletdata.TxInput{PreviousOutpoint: txIn.PreviousOutPoint.String()}
+	}
+	for i, txOut := range msgTx.TxOut {
+		output := &walletdata.TxOutput{
+			Index:  uint32(i),
+			Amount: txOut.Value,
+		}
+		if _, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript, chainParams); len(addrs) == 1 {
+			output.Address = addrs[0].String()
+		}
+		txSummary.Outputs[i] = output
+	}
+	for i, in := range summary.MyInputs {
+		txSummary.MyInputs[i] = walletdata.MyTxInput{
+			Index:   in.Index,
+			Amount:  int64(in.PreviousAmount),
+			Account: in.PreviousAccount,
+		}
+	}
+	for i, out := range summary.MyOutputs {
+		txSummary.MyOutputs[i] = walletdata.MyTxOutput{
+			Index:    out.Index,
+			Account:  out.Account,
+			IsChange: out.Internal,
+		}
+	}
+
+	return txSummary.Transaction(), nil
+}
+
+// isMixTx returns true if tx looks like a CoinShuffle++ mix transaction, which
+// has at least 3 inputs and at least 3 outputs of the same mix denomination
+// that make up at least half of its outputs.
+func isMixTx(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) < 3 || len(tx.TxOut) < 3 {
+		return false
+	}
+
+	mixedOutputs := make(map[int64]int)
+	for _, txOut := range tx.TxOut {
+		if _, ok := mixDenominations[txOut.Value]; ok {
+			mixedOutputs[txOut.Value]++
+		}
+	}
+	var mixCount int
+	for _, count := range mixedOutputs {
+		if count >= 3 && count > mixCount {
+			mixCount = count
+		}
+	}
+	return mixCount > 0 && mixCount >= len(tx.TxOut)/2
+}
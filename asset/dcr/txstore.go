@@ -0,0 +1,46 @@
+package dcr
+
+import (
+	"context"
+	"fmt"
+
+	"decred.org/dcrwallet/v3/wallet/udb"
+	"decred.org/dcrwallet/v3/wallet/walletdb"
+)
+
+// txStore returns the wallet database and its transaction store, which the
+// main wallet does not expose. The store is opened once and kept until the
+// wallet is closed, because opening it derives the wallet's public keys.
+func (w *Wallet[_]) txStore(ctx context.Context) (walletdb.DB, *udb.Store, error) {
+	w.txStoreMtx.Lock()
+	defer w.txStoreMtx.Unlock()
+
+	db, ok := w.db.(walletdb.DB)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported wallet database type %T", w.db)
+	}
+	if w.openedTxStore == nil {
+		_, txStore, _, err := udb.Open(ctx, db, w.chainParams, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening wallet tx store: %w", err)
+		}
+		w.openedTxStore = txStore
+	}
+	return db, w.openedTxStore, nil
+}
+
+// rollbackMainChain removes every block but the genesis block from the wallet's
+// main chain, so that the block headers and cfilters are fetched and validated
+// again the next time the wallet syncs. Headers and cfilters are stored by
+// block hash, so the re-fetched ones replace the stored ones. The transactions
+// mined in the removed blocks become unmined until dcrwallet rescans them from
+// its rescan point, which is rolled back to the genesis block too.
+func (w *Wallet[_]) rollbackMainChain(ctx context.Context) error {
+	db, txStore, err := w.txStore(ctx)
+	if err != nil {
+		return err
+	}
+	return walletdb.Update(ctx, db, func(dbtx walletdb.ReadWriteTx) error {
+		return txStore.Rollback(dbtx, 1)
+	})
+}
@@ -11,21 +11,53 @@ import (
 	"github.com/itswisdomagain/libwallet/asset"
 )
 
+// ParseChainParams returns the chain params for the specified network. There
+// is no dcr signet, so an error is returned for the Signet network.
 func ParseChainParams(network asset.Network) (*chaincfg.Params, error) {
 	// Get network settings. Zero value is mainnet, but unknown non-zero cfg.Net
 	// is an error.
 	switch network {
 	case asset.Simnet:
 		return chaincfg.SimNetParams(), nil
+	case asset.Regtest:
+		return chaincfg.RegNetParams(), nil
 	case asset.Testnet:
 		return chaincfg.TestNet3Params(), nil
 	case asset.Mainnet:
 		return chaincfg.MainNetParams(), nil
+	case asset.Signet:
+		return nil, fmt.Errorf("%w: dcr does not have a %s", asset.ErrUnsupportedNetwork, network)
 	default:
 		return nil, fmt.Errorf("unknown network ID: %d", uint8(network))
 	}
 }
 
+// MigrateLegacyNetwork converts a network persisted by versions of libwallet in
+// which asset.Simnet was an alias of asset.Regtest, and selected the dcr simnet.
+// Such networks were persisted as asset.Regtest and are returned as
+// asset.Simnet. Other networks are returned unchanged. Apps must only call this
+// once for each persisted dcr network, and persist the result.
+func MigrateLegacyNetwork(network asset.Network) asset.Network {
+	if network == asset.Regtest {
+		return asset.Simnet
+	}
+	return network
+}
+
+// parseWalletChainParams returns the chain params to use for a wallet created
+// or loaded with the provided params. The custom chain params registered with
+// params.ChainName are returned if a chain name is provided. A signet config
+// is rejected, since dcr does not support signet.
+func parseWalletChainParams[Tx any](params asset.OpenWalletParams[Tx]) (*chaincfg.Params, error) {
+	if params.Signet != nil {
+		return nil, fmt.Errorf("%w: dcr does not have a signet", asset.ErrUnsupportedNetwork)
+	}
+	if params.ChainName != "" {
+		return customChainParams.Lookup(params.ChainName)
+	}
+	return ParseChainParams(params.Net)
+}
+
 // extendAddresses ensures that the internal and external branches have been
 // extended to the specified indices. This can be used at wallet restoration to
 // ensure that no duplicates are encountered with existing but unused addresses.
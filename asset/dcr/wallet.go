@@ -2,11 +2,16 @@ package dcr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"decred.org/dcrwallet/v3/spv"
 	"decred.org/dcrwallet/v3/wallet"
+	"decred.org/dcrwallet/v3/wallet/udb"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/slog"
 	"github.com/itswisdomagain/libwallet/asset"
@@ -19,12 +24,32 @@ type Wallet[Tx any] struct {
 	dir         string
 	dbDriver    string
 	chainParams *chaincfg.Params
+	proxy       *asset.ProxyConfig
 	log         slog.Logger
 
 	db wallet.DB
 	*mainWallet
 
-	syncer *spv.Syncer
+	// openedTxStore is the transaction store of db, opened on first use by
+	// txStore.
+	txStoreMtx    sync.Mutex
+	openedTxStore *udb.Store
+
+	// syncer and peerManager are set while sync is active. They are written
+	// by the sync goroutine and read concurrently by other methods.
+	syncer      atomic.Pointer[spv.Syncer]
+	peerManager atomic.Pointer[asset.SPVPeerManager]
+
+	// watchScanHeight is the next height at which the watched scripts and
+	// outpoints are matched against blocks. It is kept across syncs so that
+	// matches are not emitted again when sync is restarted, and is only set
+	// once watchScanStarted is set by the first sync. watchScanRewound is set
+	// if it was lowered for newly watched scripts or outpoints.
+	watchMtx         sync.Mutex
+	watchScanHeight  int32
+	watchScanStarted bool
+	watchScanRewound bool
+	watchScanSignal  chan struct{}
 }
 
 // MainWallet returns the main dcr wallet with the core wallet functionalities.
@@ -81,6 +106,9 @@ func (w *Wallet[_]) CloseWallet() error {
 	w.log.Info("Wallet closed")
 	w.mainWallet = nil
 	w.db = nil
+	w.txStoreMtx.Lock()
+	w.openedTxStore = nil
+	w.txStoreMtx.Unlock()
 	return nil
 }
 
@@ -88,3 +116,61 @@ func (w *Wallet[_]) CloseWallet() error {
 func (w *Wallet[_]) Shutdown() error {
 	return w.CloseWallet()
 }
+
+// DeleteWallet deletes the files created by the wallet backend, the SPV address
+// manager, the SPV peer manager and the log rotator from the wallet's data
+// directory. The wallet must be closed before it can be deleted. If
+// keepWalletData is false, the walletdata database is also deleted if it is
+// stored in the data directory as asset.WalletDataDBFileName. Other files in
+// the data directory are not deleted.
+func (w *Wallet[_]) DeleteWallet(keepWalletData bool) error {
+	if w.IsSyncingOrSynced() {
+		return asset.ErrSyncInProgress
+	}
+	if w.mainWallet != nil || w.db != nil {
+		return asset.ErrWalletOpen
+	}
+
+	w.log.Info("Deleting wallet")
+	return asset.DeleteWalletFiles(w.dir, backendFiles, keepWalletData)
+}
+
+// ResetChainData deletes the peer addresses saved by the SPV address manager
+// and rolls the wallet's main chain back to the genesis block, which discards
+// dcrwallet's sync state. The block headers and cfilters are fetched again and
+// the wallet's transactions are rescanned when StartSync is next called.
+// Indexed transactions above the birthday block are deleted, so that they are
+// re-indexed after the rescan. The wallet must be open and sync must not be
+// active.
+func (w *Wallet[_]) ResetChainData() error {
+	if w.IsSyncingOrSynced() {
+		return asset.ErrSyncInProgress
+	}
+	if w.mainWallet == nil {
+		return asset.ErrWalletNotOpen
+	}
+
+	w.log.Info("Resetting chain data")
+	err := os.Remove(filepath.Join(w.dir, addrmgrPeersFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting %s: %w", addrmgrPeersFileName, err)
+	}
+
+	if err := w.rollbackMainChain(context.Background()); err != nil {
+		return fmt.Errorf("error rolling back wallet chain: %w", err)
+	}
+
+	if w.TxIndexDB != nil {
+		// The birthday block is 0 if the birthday is not yet mapped to a
+		// block, in which case all indexed transactions are deleted.
+		birthdayHeight, mapped := w.BirthdayBlock()
+		if !mapped {
+			birthdayHeight = 0
+		}
+		if err := w.RollbackTxIndexLastBlock(birthdayHeight); err != nil {
+			return fmt.Errorf("error rolling back tx index: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package dcr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"decred.org/dcrwallet/v3/wallet"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+	"github.com/itswisdomagain/libwallet/asset"
+)
+
+// watchScanInterval is how often the watched scripts and outpoints are
+// matched against new blocks, in addition to every time a block is connected.
+const watchScanInterval = time.Minute
+
+// WatchAddress watches addr, which need not belong to the wallet, for
+// transactions that pay to it in blocks from startHeight. Matches are delivered
+// as asset.WatchMatchEvents while the wallet is syncing.
+func (w *Wallet[_]) WatchAddress(addr string, startHeight int32) error {
+	address, err := stdaddr.DecodeAddress(addr, w.chainParams)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	_, pkScript := address.PaymentScript()
+	w.WatchScript(pkScript, startHeight)
+	return nil
+}
+
+// UnwatchAddress stops watching addr.
+func (w *Wallet[_]) UnwatchAddress(addr string) error {
+	address, err := stdaddr.DecodeAddress(addr, w.chainParams)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	_, pkScript := address.PaymentScript()
+	w.UnwatchScript(pkScript)
+	return nil
+}
+
+// WatchScript watches pkScript for transactions that pay to it in blocks from
+// startHeight. Matches are delivered as asset.WatchMatchEvents while the
+// wallet is syncing.
+func (w *Wallet[_]) WatchScript(pkScript []byte, startHeight int32) {
+	w.WatchList().AddScript(pkScript, startHeight)
+	w.rewindWatchScan(startHeight)
+}
+
+// UnwatchScript stops watching pkScript.
+func (w *Wallet[_]) UnwatchScript(pkScript []byte) {
+	w.WatchList().RemoveScript(pkScript)
+}
+
+// WatchOutpoint watches outpoint, whose output script is pkScript, for
+// transactions that spend it in blocks from startHeight. Matches are delivered
+// as asset.WatchMatchEvents while the wallet is syncing.
+func (w *Wallet[_]) WatchOutpoint(outpoint wire.OutPoint, pkScript []byte, startHeight int32) {
+	w.WatchList().AddOutpoint(outpoint.String(), pkScript, startHeight)
+	w.rewindWatchScan(startHeight)
+}
+
+// UnwatchOutpoint stops watching outpoint.
+func (w *Wallet[_]) UnwatchOutpoint(outpoint wire.OutPoint) {
+	w.WatchList().RemoveOutpoint(outpoint.String())
+}
+
+// rewindWatchScan causes the blocks from startHeight to be matched against the
+// watched scripts and outpoints if they were already scanned.
+func (w *Wallet[_]) rewindWatchScan(startHeight int32) {
+	w.watchMtx.Lock()
+	if w.watchScanStarted && startHeight < w.watchScanHeight {
+		w.watchScanHeight = startHeight
+		w.watchScanRewound = true
+	}
+	w.watchMtx.Unlock()
+	w.signalWatchScan()
+}
+
+// signalWatchScan wakes the watch scan, if it is waiting.
+func (w *Wallet[_]) signalWatchScan() {
+	w.watchMtx.Lock()
+	signal := w.watchScanSignal
+	w.watchMtx.Unlock()
+
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+// scanWatchList matches the watched scripts and outpoints against the cfilters
+// of the wallet's main chain blocks whenever new blocks are connected until ctx
+// is canceled. Blocks are only scanned while the wallet is synced. The scan
+// continues from where the previous sync left it, or starts from the lowest
+// watched start height on the first sync.
+func (w *Wallet[_]) scanWatchList(ctx context.Context) {
+	signal := make(chan struct{}, 1)
+	w.watchMtx.Lock()
+	if !w.watchScanStarted {
+		startHeight, watching := w.WatchList().StartHeight()
+		if !watching {
+			_, tipHeight := w.MainChainTip(ctx)
+			startHeight = tipHeight + 1
+		}
+		w.watchScanHeight = startHeight
+		w.watchScanStarted = true
+	}
+	w.watchScanRewound = false
+	w.watchScanSignal = signal
+	w.watchMtx.Unlock()
+
+	sub := w.SubscribeEvents(0)
+	defer sub.Unsubscribe()
+	ticker := time.NewTicker(watchScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if w.IsSynced() {
+			if err := w.scanWatchedBlocks(ctx); err != nil && ctx.Err() == nil {
+				w.log.Errorf("error matching watched scripts: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Events():
+		case <-signal:
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanWatchedBlocks matches the watched scripts and outpoints against the
+// blocks from the watch scan height up to the wallet's main chain tip.
+func (w *Wallet[_]) scanWatchedBlocks(ctx context.Context) error {
+	_, tipHeight := w.MainChainTip(ctx)
+
+	w.watchMtx.Lock()
+	height := w.watchScanHeight
+	w.watchMtx.Unlock()
+
+	for height <= tipHeight {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		scripts := w.WatchList().FilterScripts()
+		if len(scripts) == 0 {
+			height = tipHeight + 1
+		} else if err := w.scanWatchedBlock(ctx, height, scripts); err != nil {
+			return err
+		} else {
+			height++
+		}
+
+		// Continue from the rewind height if the scan was rewound for newly
+		// watched scripts or outpoints.
+		w.watchMtx.Lock()
+		if w.watchScanRewound {
+			height = w.watchScanHeight
+			w.watchScanRewound = false
+		} else {
+			w.watchScanHeight = height
+		}
+		w.watchMtx.Unlock()
+	}
+	return nil
+}
+
+// scanWatchedBlock fetches the block at height if its cfilter matches any of
+// scripts and emits an asset.WatchMatchEvent for each transaction in the block
+// that pays to a watched script or spends a watched outpoint.
+func (w *Wallet[_]) scanWatchedBlock(ctx context.Context, height int32, scripts [][]byte) error {
+	blockInfo, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(height))
+	if err != nil {
+		return fmt.Errorf("error getting block at height %d: %w", height, err)
+	}
+	key, filter, err := w.CFilterV2(ctx, &blockInfo.Hash)
+	if err != nil {
+		return fmt.Errorf("error getting cfilter for block %s: %w", blockInfo.Hash, err)
+	}
+	if !filter.MatchAny(key, scripts) {
+		return nil
+	}
+
+	syncer := w.syncer.Load()
+	if syncer == nil {
+		return asset.ErrNotSyncing
+	}
+	blocks, err := syncer.Blocks(ctx, []*chainhash.Hash{&blockInfo.Hash})
+	if err != nil {
+		return fmt.Errorf("error fetching block %s: %w", blockInfo.Hash, err)
+	}
+
+	watchList := w.WatchList()
+	block := asset.BlockInfo{
+		Height:    height,
+		Hash:      blockInfo.Hash.String(),
+		Timestamp: time.Unix(blockInfo.Timestamp, 0),
+	}
+	msgBlock := blocks[0]
+	txs := make([]*wire.MsgTx, 0, len(msgBlock.Transactions)+len(msgBlock.STransactions))
+	txs = append(txs, msgBlock.Transactions...)
+	txs = append(txs, msgBlock.STransactions...)
+	for _, tx := range txs {
+		match := &asset.WatchMatchEvent{
+			TxHash: tx.TxHash().String(),
+			Block:  block,
+		}
+		for i, txOut := range tx.TxOut {
+			if watchList.IsWatchedScript(txOut.PkScript) {
+				match.Outputs = append(match.Outputs, uint32(i))
+			}
+		}
+		for _, txIn := range tx.TxIn {
+			if outpoint := txIn.PreviousOutPoint.String(); watchList.IsWatchedOutpoint(outpoint) {
+				match.SpentOutpoints = append(match.SpentOutpoints, outpoint)
+			}
+		}
+		if len(match.Outputs) == 0 && len(match.SpentOutpoints) == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		buf.Grow(tx.SerializeSize())
+		if err := tx.Serialize(&buf); err != nil {
+			w.log.Errorf("error serializing watched tx %s: %v", match.TxHash, err)
+			continue
+		}
+		match.Tx = buf.Bytes()
+		w.NotifyWatchMatch(match)
+	}
+	return nil
+}
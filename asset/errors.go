@@ -3,5 +3,14 @@ package asset
 import "errors"
 
 var (
-	ErrInvalidPassphrase = errors.New("invalid_passphrase")
+	ErrInvalidPassphrase  = errors.New("invalid_passphrase")
+	ErrWalletOpen         = errors.New("wallet_open")
+	ErrWalletNotOpen      = errors.New("wallet_not_open")
+	ErrSyncInProgress     = errors.New("sync_in_progress")
+	ErrNotSyncing         = errors.New("not_syncing")
+	ErrNotSynced          = errors.New("not_synced")
+	ErrUnsupportedNetwork = errors.New("unsupported_network")
+	ErrPeerBanned         = errors.New("peer_banned")
+	ErrTxDoubleSpent      = errors.New("tx_double_spent")
+	ErrTxReorgedOut       = errors.New("tx_reorged_out")
 )
@@ -0,0 +1,232 @@
+package asset
+
+import (
+	"sync"
+
+	"github.com/decred/slog"
+)
+
+// DefaultEventBufferSize is the number of events buffered for a subscriber if
+// no buffer size is specified in SubscribeEvents.
+const DefaultEventBufferSize = 100
+
+// txConfirmationEventsLimit is the number of confirmations after which
+// TxConfirmationsEvents are no longer emitted for a transaction.
+const txConfirmationEventsLimit = 6
+
+// Event is implemented by the events emitted to wallet event subscribers. Use a
+// type switch to determine the type of an event.
+type Event interface {
+	isEvent()
+}
+
+// BlockConnectedEvent is emitted when a block is connected to the main chain.
+type BlockConnectedEvent struct {
+	Block BlockInfo
+}
+
+// BlockDisconnectedEvent is emitted when a block is disconnected from the main
+// chain by a reorg.
+type BlockDisconnectedEvent struct {
+	Hash string
+}
+
+// TxReceivedEvent is emitted when an unmined transaction that is relevant to
+// the wallet is received.
+type TxReceivedEvent struct {
+	TxHash string
+}
+
+// TxMinedEvent is emitted when a transaction that is relevant to the wallet is
+// mined in a block that is connected to the main chain.
+type TxMinedEvent struct {
+	TxHash string
+	Block  BlockInfo
+}
+
+// TxConfirmationsEvent is emitted when the number of confirmations of a mined
+// transaction changes, until the transaction has 6 confirmations. A
+// transaction whose block is disconnected has 0 confirmations.
+type TxConfirmationsEvent struct {
+	TxHash        string
+	Confirmations int32
+}
+
+// BalanceChangedEvent is emitted when the total balance of an account changes.
+// TotalBalance is in the asset's smallest unit.
+type BalanceChangedEvent struct {
+	Account      uint32
+	TotalBalance int64
+}
+
+// WatchMatchEvent is emitted when a transaction mined in a main chain block pays
+// to a watched script or spends a watched outpoint. See WatchList.
+type WatchMatchEvent struct {
+	TxHash string
+	// Tx is the serialized transaction.
+	Tx    []byte
+	Block BlockInfo
+	// Outputs are the indexes of the transaction's outputs that pay to watched
+	// scripts.
+	Outputs []uint32
+	// SpentOutpoints are the watched outpoints spent by the transaction.
+	SpentOutpoints []string
+}
+
+func (*BlockConnectedEvent) isEvent()    {}
+func (*BlockDisconnectedEvent) isEvent() {}
+func (*TxReceivedEvent) isEvent()        {}
+func (*TxMinedEvent) isEvent()           {}
+func (*TxConfirmationsEvent) isEvent()   {}
+func (*BalanceChangedEvent) isEvent()    {}
+func (*WatchMatchEvent) isEvent()        {}
+
+// EventSubscription receives wallet events until Unsubscribe is called.
+type EventSubscription struct {
+	id uint64
+	ch chan Event
+	eh *eventHub
+}
+
+// Events returns the channel on which events are delivered. The channel is
+// closed when Unsubscribe is called. Events are dropped if the subscriber does
+// not keep up and the channel's buffer is full.
+func (s *EventSubscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Unsubscribe stops the delivery of events and closes the events channel.
+func (s *EventSubscription) Unsubscribe() {
+	s.eh.mtx.Lock()
+	defer s.eh.mtx.Unlock()
+
+	if _, subscribed := s.eh.subscribers[s.id]; subscribed {
+		delete(s.eh.subscribers, s.id)
+		close(s.ch)
+	}
+}
+
+// minedTx is a transaction whose confirmations are reported to subscribers.
+type minedTx struct {
+	blockHash string
+	height    int32
+}
+
+// eventHub delivers wallet events to subscribers. Asset backends translate
+// their wallet's notifications into calls to the Notify methods.
+type eventHub struct {
+	log slog.Logger
+
+	mtx         sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]*EventSubscription
+	// minedTxs are the recently mined transactions, keyed by tx hash.
+	minedTxs map[string]*minedTx
+}
+
+func newEventHub(log slog.Logger) *eventHub {
+	return &eventHub{
+		log:         log,
+		subscribers: make(map[uint64]*EventSubscription),
+		minedTxs:    make(map[string]*minedTx),
+	}
+}
+
+// SubscribeEvents returns a subscription that receives the wallet's block,
+// transaction and balance events, which are emitted while the wallet is
+// syncing. Up to bufferSize events are buffered for the subscriber,
+// DefaultEventBufferSize is used if bufferSize is not positive.
+func (eh *eventHub) SubscribeEvents(bufferSize int) *EventSubscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+
+	eh.nextID++
+	sub := &EventSubscription{
+		id: eh.nextID,
+		ch: make(chan Event, bufferSize),
+		eh: eh,
+	}
+	eh.subscribers[sub.id] = sub
+	return sub
+}
+
+// publish delivers events to all subscribers without blocking. eh.mtx MUST be
+// held.
+func (eh *eventHub) publish(events ...Event) {
+	for _, sub := range eh.subscribers {
+		for _, event := range events {
+			select {
+			case sub.ch <- event:
+			default:
+				eh.log.Warnf("Event subscriber %d is not keeping up, dropping %T", sub.id, event)
+			}
+		}
+	}
+}
+
+// NotifyBlockConnected emits a BlockConnectedEvent for block, a TxMinedEvent
+// for each of the relevant transactions mined in the block and a
+// TxConfirmationsEvent for each recently mined transaction.
+func (eh *eventHub) NotifyBlockConnected(block BlockInfo, minedTxHashes []string) {
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+
+	events := []Event{&BlockConnectedEvent{Block: block}}
+	for txHash, tx := range eh.minedTxs {
+		confirmations := block.Height - tx.height + 1
+		if confirmations <= 1 {
+			continue
+		}
+		events = append(events, &TxConfirmationsEvent{TxHash: txHash, Confirmations: confirmations})
+		if confirmations >= txConfirmationEventsLimit {
+			delete(eh.minedTxs, txHash)
+		}
+	}
+	for _, txHash := range minedTxHashes {
+		events = append(events, &TxMinedEvent{TxHash: txHash, Block: block})
+		eh.minedTxs[txHash] = &minedTx{blockHash: block.Hash, height: block.Height}
+	}
+	eh.publish(events...)
+}
+
+// NotifyBlockDisconnected emits a BlockDisconnectedEvent for the block with the
+// specified hash and a TxConfirmationsEvent for each recently mined transaction
+// in the block.
+func (eh *eventHub) NotifyBlockDisconnected(blockHash string) {
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+
+	events := []Event{&BlockDisconnectedEvent{Hash: blockHash}}
+	for txHash, tx := range eh.minedTxs {
+		if tx.blockHash == blockHash {
+			events = append(events, &TxConfirmationsEvent{TxHash: txHash})
+			delete(eh.minedTxs, txHash)
+		}
+	}
+	eh.publish(events...)
+}
+
+// NotifyTxReceived emits a TxReceivedEvent for an unmined transaction.
+func (eh *eventHub) NotifyTxReceived(txHash string) {
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+	eh.publish(&TxReceivedEvent{TxHash: txHash})
+}
+
+// NotifyBalanceChanged emits a BalanceChangedEvent for account.
+func (eh *eventHub) NotifyBalanceChanged(account uint32, totalBalance int64) {
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+	eh.publish(&BalanceChangedEvent{Account: account, TotalBalance: totalBalance})
+}
+
+// NotifyWatchMatch emits a WatchMatchEvent.
+func (eh *eventHub) NotifyWatchMatch(match *WatchMatchEvent) {
+	eh.mtx.Lock()
+	defer eh.mtx.Unlock()
+	eh.publish(match)
+}
@@ -0,0 +1,81 @@
+package ltc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// customChainParams holds the custom chain params that can be selected by name
+// when creating or loading a wallet.
+var customChainParams asset.ChainParamsRegistry[*chaincfg.Params]
+
+// RegisterChainParams registers custom chain params for a private network. The
+// params can then be selected when creating or loading a wallet by setting
+// asset.OpenWalletParams.ChainName to params.Name.
+func RegisterChainParams(params *chaincfg.Params) error {
+	return customChainParams.Register(params.Name, params)
+}
+
+// RegisterCustomChainParams creates and registers chain params for a private
+// network from the provided description. See RegisterChainParams.
+func RegisterCustomChainParams(cfg *asset.CustomChainParams) (*chaincfg.Params, error) {
+	baseNet, err := asset.NetFromString(cfg.BaseNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base network: %w", err)
+	}
+	baseParams, err := ParseChainParams(baseNet)
+	if err != nil {
+		return nil, err
+	}
+
+	params := *baseParams
+	params.Name = cfg.Name
+	if cfg.NetMagic != 0 {
+		params.Net = wire.BitcoinNet(cfg.NetMagic)
+	}
+	if cfg.DefaultPort != "" {
+		params.DefaultPort = cfg.DefaultPort
+	}
+	if len(cfg.DNSSeeds) > 0 {
+		params.DNSSeeds = make([]chaincfg.DNSSeed, 0, len(cfg.DNSSeeds))
+		for _, host := range cfg.DNSSeeds {
+			params.DNSSeeds = append(params.DNSSeeds, chaincfg.DNSSeed{Host: host})
+		}
+	}
+	if cfg.GenesisBlock != "" {
+		blockBytes, err := hex.DecodeString(cfg.GenesisBlock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis block hex: %w", err)
+		}
+		genesisBlock := new(wire.MsgBlock)
+		if err = genesisBlock.Deserialize(bytes.NewReader(blockBytes)); err != nil {
+			return nil, fmt.Errorf("invalid genesis block: %w", err)
+		}
+		genesisHash := genesisBlock.BlockHash()
+		params.GenesisBlock = genesisBlock
+		params.GenesisHash = &genesisHash
+		// The base network's checkpoints are not valid for a different chain.
+		params.Checkpoints = nil
+	}
+
+	if err = RegisterChainParams(&params); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// RegisterChainParamsFile creates and registers chain params for a private
+// network from the JSON-encoded asset.CustomChainParams in the specified file.
+// See RegisterChainParams.
+func RegisterChainParamsFile(filePath string) (*chaincfg.Params, error) {
+	cfg, err := asset.ReadCustomChainParamsFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return RegisterCustomChainParams(cfg)
+}
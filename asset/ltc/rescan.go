@@ -0,0 +1,197 @@
+package ltc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	ltcwaddrmgr "github.com/ltcsuite/ltcwallet/waddrmgr"
+	"github.com/ltcsuite/ltcwallet/walletdb"
+	"github.com/ltcsuite/ltcwallet/wtxmgr"
+)
+
+var wtxmgrNamespace = []byte("wtxmgr")
+
+// rescanProgressInterval is how often the progress of a rescan is checked.
+const rescanProgressInterval = time.Second
+
+// Rescan rescans the blockchain for the wallet's transactions, starting from
+// the specified block height. This method blocks until the rescan completes,
+// the provided ctx is canceled or CancelRescan is called. Rescan progress is
+// reported to the provided listener, if it is not nil. The wallet must be
+// synced before a rescan can be started.
+//
+// The rescan is performed by the neutrino chain client, which continues to
+// keep the wallet in sync after the rescan. Canceling ctx or calling
+// CancelRescan aborts the rescan by restarting the chain client's rescan from
+// the best block, skipping the blocks that were not yet rescanned. Stopping
+// sync also aborts the rescan, but it'll be resumed when sync is restarted.
+func (w *Wallet[_]) Rescan(ctx context.Context, fromHeight int32, listener asset.RescanProgressListener) error {
+	if !w.IsSynced() {
+		return asset.ErrNotSynced
+	}
+
+	ctx, err := w.InitializeRescanContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer w.RescanEnded()
+
+	chainClient := w.chainClient.Load()
+	if chainClient == nil {
+		return asset.ErrNotSynced
+	}
+	startHash, err := chainClient.GetBlockHash(int64(fromHeight))
+	if err != nil {
+		return fmt.Errorf("error getting block hash for height %d: %w", fromHeight, err)
+	}
+	startHeader, err := chainClient.GetBlockHeader(startHash)
+	if err != nil {
+		return fmt.Errorf("error getting block header for height %d: %w", fromHeight, err)
+	}
+
+	// Rewind the wallet's synced-to block to the rescan start block. The
+	// wallet will mark the rescanned blocks as synced as the rescan progresses.
+	var addrs []ltcutil.Address
+	var unspent []wtxmgr.Credit
+	err = walletdb.Update(w.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespace)
+		err := w.Manager.ForEachRelevantActiveAddress(addrmgrNs, func(addr ltcutil.Address) error {
+			addrs = append(addrs, addr)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		unspent, err = w.TxStore.UnspentOutputs(dbtx.ReadBucket(wtxmgrNamespace))
+		if err != nil {
+			return err
+		}
+		return w.Manager.SetSyncedTo(addrmgrNs, &ltcwaddrmgr.BlockStamp{
+			Height:    fromHeight,
+			Hash:      *startHash,
+			Timestamp: startHeader.Timestamp,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error preparing wallet for rescan: %w", err)
+	}
+
+	w.log.Infof("Rescanning from block %d", fromHeight)
+	w.mainWallet.SetChainSynced(false)
+
+	// The wallet's Rescan method blocks until the rescan is complete, so run
+	// it in a goroutine and report the rescan progress while waiting.
+	rescanErr := make(chan error, 1)
+	go func() {
+		rescanErr <- w.mainWallet.Rescan(addrs, unspent)
+	}()
+
+	reportProgress := func() {
+		if listener == nil {
+			return
+		}
+		bestBlock, err := w.chainService.Load().BestBlock()
+		if err != nil {
+			w.log.Errorf("error getting best block: %v", err)
+			return
+		}
+		listener(&asset.RescanProgress{
+			StartHeight:    fromHeight,
+			ScannedThrough: w.Manager.SyncedTo().Height,
+			EndHeight:      bestBlock.Height,
+		})
+	}
+
+	ticker := time.NewTicker(rescanProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if w.IsSyncingOrSynced() && !w.SyncIsStopping() {
+				w.abortRescan(addrs, unspent)
+			}
+			return ctx.Err()
+		case err := <-rescanErr:
+			if err != nil {
+				return fmt.Errorf("rescan error: %w", err)
+			}
+			reportProgress()
+			w.log.Info("Rescan complete")
+			return nil
+		case <-ticker.C:
+			reportProgress()
+		}
+	}
+}
+
+// abortRescan stops a rescan that is in progress by moving the wallet's
+// synced-to block to the best block and restarting the chain client's rescan
+// from there, so that the chain client only follows new blocks.
+func (w *Wallet[_]) abortRescan(addrs []ltcutil.Address, unspent []wtxmgr.Credit) {
+	w.log.Info("Aborting rescan")
+	chainService := w.chainService.Load()
+	bestBlock, err := chainService.BestBlock()
+	if err != nil {
+		w.log.Errorf("error getting best block to abort rescan: %v", err)
+		return
+	}
+	bestHeader, err := chainService.GetBlockHeader(&bestBlock.Hash)
+	if err != nil {
+		w.log.Errorf("error getting best block header to abort rescan: %v", err)
+		return
+	}
+
+	outpoints := make(map[wire.OutPoint]ltcutil.Address, len(unspent))
+	for _, output := range unspent {
+		_, outputAddrs, _, err := txscript.ExtractPkScriptAddrs(output.PkScript, w.ChainParams())
+		if err != nil || len(outputAddrs) == 0 {
+			continue
+		}
+		outpoints[output.OutPoint] = outputAddrs[0]
+	}
+
+	err = walletdb.Update(w.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		return w.Manager.SetSyncedTo(dbtx.ReadWriteBucket(waddrmgrNamespace), &ltcwaddrmgr.BlockStamp{
+			Height:    bestBlock.Height,
+			Hash:      bestBlock.Hash,
+			Timestamp: bestHeader.Timestamp,
+		})
+	})
+	if err != nil {
+		w.log.Errorf("error updating synced-to block to abort rescan: %v", err)
+		return
+	}
+
+	chainClient := w.chainClient.Load()
+	if chainClient == nil {
+		return
+	}
+	if err = chainClient.Rescan(&bestBlock.Hash, addrs, outpoints); err != nil {
+		w.log.Errorf("error restarting chain client rescan to abort rescan: %v", err)
+	}
+}
+
+// RescanFromBirthday rescans the blockchain for the wallet's transactions,
+// starting from the wallet's birthday block. See Rescan for more details.
+func (w *Wallet[_]) RescanFromBirthday(ctx context.Context, listener asset.RescanProgressListener) error {
+	var birthdayHeight int32
+	err := walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		birthdayBlock, _, err := w.Manager.BirthdayBlock(dbtx.ReadBucket(waddrmgrNamespace))
+		if ltcwaddrmgr.IsError(err, ltcwaddrmgr.ErrBirthdayBlockNotSet) {
+			return nil // rescan from the genesis block
+		}
+		birthdayHeight = birthdayBlock.Height
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error reading wallet birthday block: %w", err)
+	}
+
+	return w.Rescan(ctx, birthdayHeight, listener)
+}
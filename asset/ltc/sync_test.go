@@ -0,0 +1,90 @@
+package ltc
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/slog"
+	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/itswisdomagain/libwallet/internal/leakcheck"
+	"github.com/itswisdomagain/libwallet/walletdata"
+)
+
+// TestSyncRestartsDoNotLeakGoroutines starts and stops sync repeatedly and
+// checks that every goroutine started by a sync exits when it stops.
+func TestSyncRestartsDoNotLeakGoroutines(t *testing.T) {
+	const syncCycles = 5
+
+	dir := t.TempDir()
+	db, err := walletdata.Initialize[struct{}](filepath.Join(dir, "walletdata.db"), nil)
+	if err != nil {
+		t.Fatalf("error creating wallet data db: %v", err)
+	}
+
+	ctx := context.Background()
+	w, err := CreateWallet(ctx, asset.CreateWalletParams[struct{}]{
+		OpenWalletParams: asset.OpenWalletParams[struct{}]{
+			Net:            asset.Regtest,
+			DataDir:        dir,
+			DbDriver:       "bdb",
+			Logger:         slog.Disabled,
+			UserConfigDB:   db,
+			WalletConfigDB: db,
+			// Stop the chain service with every sync, so that its goroutines
+			// must exit too.
+			StopChainServiceOnSyncEnd: true,
+		},
+		Pass:     []byte("pass"),
+		Birthday: time.Now(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("error creating wallet: %v", err)
+	}
+	defer w.CloseWallet()
+
+	states := make(chan asset.SyncState, 100)
+	err = w.AddSyncStateListener("test", func(status asset.SyncStatus) {
+		states <- status.State
+	})
+	if err != nil {
+		t.Fatalf("error adding sync state listener: %v", err)
+	}
+	waitForState := func(state asset.SyncState) {
+		t.Helper()
+		for {
+			select {
+			case s := <-states:
+				if s == state {
+					return
+				}
+			case <-time.After(time.Minute):
+				t.Fatalf("timed out waiting for sync state %s", state)
+			}
+		}
+	}
+
+	// Nothing listens on port 1, so sync keeps trying to connect to the peer
+	// until it is stopped. Sync only becomes idle once every goroutine that it
+	// started has exited.
+	syncOnce := func() {
+		t.Helper()
+		if err := w.StartSync(ctx, asset.PeerModeConnectOnly, "127.0.0.1:1"); err != nil {
+			t.Fatalf("error starting sync: %v", err)
+		}
+		waitForState(asset.SyncStateConnecting)
+		w.StopSync()
+		waitForState(asset.SyncStateIdle)
+	}
+
+	// The first sync starts goroutines that live as long as the wallet.
+	syncOnce()
+	baseline := leakcheck.Snapshot()
+
+	for i := 0; i < syncCycles; i++ {
+		syncOnce()
+	}
+
+	leakcheck.Check(t, baseline, 5*time.Second)
+}
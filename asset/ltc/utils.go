@@ -10,6 +10,8 @@ import (
 	"github.com/ltcsuite/ltcwallet/walletdb"
 )
 
+// ParseChainParams returns the chain params for the specified network. There
+// is no ltc signet, so an error is returned for the Signet network.
 func ParseChainParams(net asset.Network) (*chaincfg.Params, error) {
 	switch net {
 	case asset.Mainnet:
@@ -18,8 +20,26 @@ func ParseChainParams(net asset.Network) (*chaincfg.Params, error) {
 		return &chaincfg.TestNet4Params, nil
 	case asset.Regtest:
 		return &chaincfg.RegressionNetParams, nil
+	case asset.Simnet:
+		return &chaincfg.SimNetParams, nil
+	case asset.Signet:
+		return nil, fmt.Errorf("%w: ltc does not have a %s", asset.ErrUnsupportedNetwork, net)
 	}
-	return nil, fmt.Errorf("unknown network ID %v", net)
+	return nil, fmt.Errorf("unknown network ID %d", uint8(net))
+}
+
+// parseWalletChainParams returns the chain params to use for a wallet created
+// or loaded with the provided params. The custom chain params registered with
+// params.ChainName are returned if a chain name is provided. A signet config
+// is rejected, since ltc does not support signet.
+func parseWalletChainParams[Tx any](params asset.OpenWalletParams[Tx]) (*chaincfg.Params, error) {
+	if params.Signet != nil {
+		return nil, fmt.Errorf("%w: ltc does not have a signet", asset.ErrUnsupportedNetwork)
+	}
+	if params.ChainName != "" {
+		return customChainParams.Lookup(params.ChainName)
+	}
+	return ParseChainParams(params.Net)
 }
 
 func extendAddresses(extIdx, intIdx uint32, ltcw *wallet.Wallet) error {
@@ -39,3 +59,29 @@ func extendAddresses(extIdx, intIdx uint32, ltcw *wallet.Wallet) error {
 		return nil
 	})
 }
+
+// rewindToBirthday sets the wallet's synced-to block to its birthday block, or
+// to the block the wallet was created at if the birthday block is not yet
+// known. Returns the height of the block the wallet was rewound to.
+func rewindToBirthday(ltcw *wallet.Wallet) (int32, error) {
+	var rewindHeight int32
+	err := walletdb.Update(ltcw.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		ns := dbtx.ReadWriteBucket(waddrmgrNamespace)
+		birthdayBlock, _, err := ltcw.Manager.BirthdayBlock(ns)
+		if ltcwaddrmgr.IsError(err, ltcwaddrmgr.ErrBirthdayBlockNotSet) {
+			// Passing a nil block stamp resets the synced-to block to the
+			// wallet's start block.
+			if err = ltcw.Manager.SetSyncedTo(ns, nil); err != nil {
+				return err
+			}
+			rewindHeight = ltcw.Manager.SyncedTo().Height
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rewindHeight = birthdayBlock.Height
+		return ltcw.Manager.SetSyncedTo(ns, &birthdayBlock)
+	})
+	return rewindHeight, err
+}
@@ -1,12 +1,19 @@
 package ltc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	neutrino "github.com/dcrlabs/neutrino-ltc"
 	"github.com/dcrlabs/neutrino-ltc/chain"
 	"github.com/decred/slog"
 	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/itswisdomagain/libwallet/assetlog"
 	"github.com/ltcsuite/ltcwallet/wallet"
 	"github.com/ltcsuite/ltcwallet/walletdb"
 	_ "github.com/ltcsuite/ltcwallet/walletdb/bdb"
@@ -20,13 +27,50 @@ type Wallet[Tx any] struct {
 	*asset.WalletBase[Tx]
 	*mainWallet
 
-	dir          string
-	dbDriver     string
+	dir      string
+	dbDriver string
+	proxy    *asset.ProxyConfig
+	// connectPeers are the peers that the chain service was created to
+	// connect to exclusively. Empty if peer discovery is enabled.
+	connectPeers []string
 	log          slog.Logger
 	loader       *wallet.Loader
 	db           walletdb.DB
-	chainService *neutrino.ChainService
-	chainClient  *chain.NeutrinoClient
+
+	// chainService and chainClient are replaced when sync is started or
+	// restarted and when the chain data is reset, while other methods and the
+	// sync goroutines may be reading them.
+	chainService atomic.Pointer[neutrino.ChainService]
+	chainClient  atomic.Pointer[chain.NeutrinoClient]
+
+	// peerManager is set while sync is active. It is written by the sync
+	// goroutines and read concurrently by other methods.
+	peerManager atomic.Pointer[asset.SPVPeerManager]
+
+	// stopChainServiceOnSyncEnd causes the chain service to be stopped when
+	// sync ends. chainServiceStopped is then set so that the chain service is
+	// rebuilt on the next sync, because neutrino cannot restart it.
+	// chainServiceStarted is set once the chain service is started by a sync.
+	stopChainServiceOnSyncEnd bool
+	chainServiceStarted       bool
+	chainServiceStopped       bool
+
+	// watchRescan matches the watched scripts and outpoints while syncing.
+	// watchRescanCtx is the ctx of the sync that it runs in and
+	// stopWatchRescan stops it.
+	watchMtx        sync.Mutex
+	watchRescan     *neutrino.Rescan
+	watchRescanCtx  context.Context
+	stopWatchRescan context.CancelFunc
+
+	// watchScanHeight is the height of the last block matched against the
+	// watched scripts and outpoints, or of the start block of the first watch
+	// rescan. It is kept across syncs so that matches are not emitted again
+	// when sync is restarted. watchScanned is false until a watch rescan is
+	// started.
+	watchScanMtx    sync.Mutex
+	watchScanHeight int32
+	watchScanned    bool
 }
 
 // MainWallet returns the main ltc wallet with the core wallet functionalities.
@@ -52,6 +96,15 @@ func (w *Wallet[_]) OpenWallet() error {
 	}
 
 	w.mainWallet = ltcw
+
+	// Wallets created before birthdays were recorded use the birthday saved
+	// by the wallet backend.
+	if w.Birthday().IsZero() {
+		if err = w.SaveBirthday(ltcw.Manager.Birthday()); err != nil {
+			w.log.Errorf("error saving wallet birthday: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -80,19 +133,120 @@ func (w *Wallet[_]) Shutdown() error {
 
 	// Closing the wallet does not stop the neutrino chain service, stop it now
 	// before closing the neutrino DB.
-	w.log.Trace("Stopping neutrino chain service")
-	if err := w.chainService.Stop(); err != nil {
-		w.log.Errorf("error stopping neutrino chain service: %v", err)
-	}
+	w.stopChainService()
 
-	w.log.Trace("Closing neutrino db")
-	if err := w.db.Close(); err != nil {
-		w.log.Errorf("error closing neutrino db: %v", err)
+	if w.db != nil {
+		w.log.Trace("Closing neutrino db")
+		if err := w.db.Close(); err != nil {
+			w.log.Errorf("error closing neutrino db: %v", err)
+		}
 	}
 
 	w.log.Info("Wallet shutdown complete")
-	w.chainClient = nil
-	w.chainService = nil
+	w.chainClient.Store(nil)
+	w.chainService.Store(nil)
 	w.db = nil
 	return nil
 }
+
+// stopChainService stops the chain service if it was started and is not yet
+// stopped, and marks it stopped. A chain service that was never started is
+// only marked stopped, because neutrino blocks forever when stopping it.
+func (w *Wallet[_]) stopChainService() {
+	chainService := w.chainService.Load()
+	if chainService == nil || w.chainServiceStopped {
+		return
+	}
+	if w.chainServiceStarted {
+		w.log.Trace("Stopping neutrino chain service")
+		if err := chainService.Stop(); err != nil {
+			w.log.Errorf("error stopping neutrino chain service: %v", err)
+		}
+	}
+	w.chainServiceStopped = true
+}
+
+// DeleteWallet deletes the files created by the wallet backend, the neutrino
+// chain service, the SPV peer manager and the log rotator from the wallet's
+// data directory. The wallet must be shut down before it can be deleted. If
+// keepWalletData is false, the walletdata database is also deleted if it is
+// stored in the data directory as asset.WalletDataDBFileName. Other files in
+// the data directory are not deleted.
+func (w *Wallet[_]) DeleteWallet(keepWalletData bool) error {
+	if w.IsSyncingOrSynced() {
+		return asset.ErrSyncInProgress
+	}
+	if w.mainWallet != nil || w.db != nil {
+		return asset.ErrWalletOpen
+	}
+
+	w.log.Info("Deleting wallet")
+	return asset.DeleteWalletFiles(w.dir, backendFiles, keepWalletData)
+}
+
+// ResetChainData deletes the block headers and filter headers downloaded by the
+// neutrino chain service and rewinds the wallet's synced-to block to its
+// birthday block. Indexed transactions above the birthday block are also
+// deleted. The chain data will be re-downloaded from scratch when StartSync is
+// next called. The wallet must be open and sync must not be active.
+func (w *Wallet[_]) ResetChainData() error {
+	if w.IsSyncingOrSynced() {
+		return asset.ErrSyncInProgress
+	}
+	if w.mainWallet == nil {
+		return asset.ErrWalletNotOpen
+	}
+
+	w.log.Info("Resetting chain data")
+	birthdayHeight, err := rewindToBirthday(w.mainWallet)
+	if err != nil {
+		return fmt.Errorf("error rewinding wallet to birthday block: %w", err)
+	}
+
+	// Stop the chain service and close the neutrino db before deleting the
+	// chain data files. The stopped chain service is only replaced once its
+	// replacement is created below, otherwise it is recreated, along with
+	// the neutrino db, by the next StartSync.
+	w.stopChainService()
+	w.chainServiceStopped = true
+	if w.db != nil {
+		if err := w.db.Close(); err != nil {
+			return fmt.Errorf("error closing neutrino db: %w", err)
+		}
+		w.db = nil
+	}
+	for _, fileName := range chainDataFiles {
+		err := os.Remove(filepath.Join(w.dir, fileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error deleting %s: %w", fileName, err)
+		}
+	}
+
+	// Recreate the chain service DB and the chain service.
+	db, err := createChainServiceDB(w.dir, w.dbDriver)
+	if err != nil {
+		return err
+	}
+	peerStore := w.PeerStore(filepath.Join(w.dir, savedPeersFileName))
+	chainService, err := initializeChainService(w.dir, db, *w.ChainParams(), w.proxy, w.connectPeers, peerStore)
+	if err != nil {
+		if err := db.Close(); err != nil {
+			w.log.Errorf("error closing neutrino db: %v", err)
+		}
+		return fmt.Errorf("unable to initialize neutrino ChainService: %w", err)
+	}
+
+	w.db = db
+	w.chainService.Store(chainService)
+	w.chainServiceStarted = false
+	w.chainServiceStopped = false
+	w.chainClient.Store(chain.NewNeutrinoClient(w.ChainParams(), chainService, &assetlog.BTCLogger{Logger: w.log}))
+
+	if w.TxIndexDB != nil {
+		if err := w.RollbackTxIndexLastBlock(birthdayHeight); err != nil {
+			return fmt.Errorf("error rolling back tx index: %w", err)
+		}
+	}
+
+	return nil
+}
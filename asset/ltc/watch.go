@@ -0,0 +1,291 @@
+package ltc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	neutrino "github.com/dcrlabs/neutrino-ltc"
+	"github.com/dcrlabs/neutrino-ltc/headerfs"
+	"github.com/itswisdomagain/libwallet/asset"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	"github.com/ltcsuite/ltcd/rpcclient"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// WatchAddress watches addr, which need not belong to the wallet, for
+// transactions that pay to it in blocks from startHeight. Matches are delivered
+// as asset.WatchMatchEvents while the wallet is syncing.
+func (w *Wallet[_]) WatchAddress(addr string, startHeight int32) error {
+	address, err := ltcutil.DecodeAddress(addr, w.ChainParams())
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+	return w.WatchScript(pkScript, startHeight)
+}
+
+// UnwatchAddress stops watching addr.
+func (w *Wallet[_]) UnwatchAddress(addr string) error {
+	address, err := ltcutil.DecodeAddress(addr, w.ChainParams())
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+	w.UnwatchScript(pkScript)
+	return nil
+}
+
+// WatchScript watches pkScript for transactions that pay to it in blocks from
+// startHeight. pkScript must pay to a single address. Matches are delivered as
+// asset.WatchMatchEvents while the wallet is syncing.
+func (w *Wallet[_]) WatchScript(pkScript []byte, startHeight int32) error {
+	address, err := scriptAddress(pkScript, w.ChainParams())
+	if err != nil {
+		return err
+	}
+	w.WatchList().AddScript(pkScript, startHeight)
+	return w.updateWatchRescan(startHeight, neutrino.AddAddrs(address))
+}
+
+// UnwatchScript stops watching pkScript.
+func (w *Wallet[_]) UnwatchScript(pkScript []byte) {
+	w.WatchList().RemoveScript(pkScript)
+}
+
+// WatchOutpoint watches outpoint, whose output script is pkScript, for
+// transactions that spend it in blocks from startHeight. Matches are delivered
+// as asset.WatchMatchEvents while the wallet is syncing.
+func (w *Wallet[_]) WatchOutpoint(outpoint wire.OutPoint, pkScript []byte, startHeight int32) error {
+	w.WatchList().AddOutpoint(outpoint.String(), pkScript, startHeight)
+	return w.updateWatchRescan(startHeight, neutrino.AddInputs(neutrino.InputWithScript{
+		OutPoint: outpoint,
+		PkScript: pkScript,
+	}))
+}
+
+// UnwatchOutpoint stops watching outpoint.
+func (w *Wallet[_]) UnwatchOutpoint(outpoint wire.OutPoint) {
+	w.WatchList().RemoveOutpoint(outpoint.String())
+}
+
+// startWatchRescan starts a neutrino rescan that matches the watched scripts
+// and outpoints and then follows the chain until ctx is canceled. The rescan
+// continues after the last block matched by a previous sync, or starts from
+// the lowest watched start height if no block was matched yet.
+func (w *Wallet[_]) startWatchRescan(ctx context.Context) {
+	w.watchMtx.Lock()
+	defer w.watchMtx.Unlock()
+	w.watchRescanCtx = ctx
+	w.startWatchRescanLocked()
+}
+
+// startWatchRescanLocked starts the watch rescan in the ctx of the current
+// sync. The watchMtx must be held.
+func (w *Wallet[_]) startWatchRescanLocked() {
+	ctx, cancel := context.WithCancel(w.watchRescanCtx)
+	w.stopWatchRescan = cancel
+
+	watchList := w.WatchList()
+	var addrs []ltcutil.Address
+	for _, pkScript := range watchList.Scripts() {
+		address, err := scriptAddress(pkScript, w.ChainParams())
+		if err != nil {
+			w.log.Errorf("error watching script %x: %v", pkScript, err)
+			continue
+		}
+		addrs = append(addrs, address)
+	}
+	var inputs []neutrino.InputWithScript
+	for outpoint, op := range watchList.Outpoints() {
+		prevOut, err := parseOutPoint(outpoint)
+		if err != nil {
+			w.log.Errorf("error watching outpoint %s: %v", outpoint, err)
+			continue
+		}
+		inputs = append(inputs, neutrino.InputWithScript{OutPoint: *prevOut, PkScript: op.PkScript})
+	}
+
+	options := []neutrino.RescanOption{
+		neutrino.NotificationHandlers(rpcclient.NotificationHandlers{
+			OnFilteredBlockConnected:    w.onWatchedBlockConnected,
+			OnFilteredBlockDisconnected: w.onWatchedBlockDisconnected,
+		}),
+		neutrino.QuitChan(ctx.Done()),
+		neutrino.WatchAddrs(addrs...),
+		neutrino.WatchInputs(inputs...),
+	}
+	// The start block is not scanned, so start from the last matched block.
+	// The first watch rescan starts from the block before the lowest start
+	// height, or from the best block if nothing is watched.
+	w.watchScanMtx.Lock()
+	if !w.watchScanned {
+		if startHeight, watching := watchList.StartHeight(); watching {
+			w.watchScanHeight, w.watchScanned = blockBefore(startHeight), true
+		} else if bestBlock, err := w.chainService.Load().BestBlock(); err == nil {
+			w.watchScanHeight, w.watchScanned = bestBlock.Height, true
+		} else {
+			w.log.Errorf("error getting best block to start watch rescan: %v", err)
+		}
+	}
+	if w.watchScanned {
+		options = append(options, neutrino.StartBlock(&headerfs.BlockStamp{Height: w.watchScanHeight}))
+	}
+	w.watchScanMtx.Unlock()
+
+	rescan := neutrino.NewRescan(&neutrino.RescanChainSource{ChainService: w.chainService.Load()}, options...)
+	errChan := rescan.Start()
+	w.watchRescan = rescan
+
+	go func() {
+		if err := <-errChan; err != nil && ctx.Err() == nil {
+			w.log.Errorf("Watch rescan ended with error: %v", err)
+		}
+		cancel()
+		w.watchMtx.Lock()
+		if w.watchRescan == rescan {
+			w.watchRescan = nil
+		}
+		w.watchMtx.Unlock()
+	}()
+}
+
+// updateWatchRescan adds newly watched scripts or outpoints to the running
+// watch rescan, rewinding it to startHeight if it already scanned past it.
+// Newly watched scripts and outpoints are included when the watch rescan is
+// started if sync is not active.
+func (w *Wallet[_]) updateWatchRescan(startHeight int32, options ...neutrino.UpdateOption) error {
+	w.watchMtx.Lock()
+	defer w.watchMtx.Unlock()
+
+	// Blocks from startHeight must be matched again, including by the next
+	// sync's watch rescan if sync is not active.
+	rewindHeight := blockBefore(startHeight)
+	w.watchScanMtx.Lock()
+	rewind := !w.watchScanned || rewindHeight < w.watchScanHeight
+	if w.watchScanned && rewind {
+		w.watchScanHeight = rewindHeight
+	}
+	w.watchScanMtx.Unlock()
+
+	if w.watchRescan == nil {
+		return nil
+	}
+	if rewind && rewindHeight == 0 {
+		// neutrino ignores rewinds to the genesis block, so restart the watch
+		// rescan from it instead. The restarted rescan watches the newly
+		// watched scripts and outpoints, which are already in the watch list.
+		w.stopWatchRescan()
+		w.watchRescan.WaitForShutdown()
+		w.startWatchRescanLocked()
+		return nil
+	}
+	if rewind {
+		options = append(options, neutrino.Rewind(uint32(rewindHeight)), neutrino.DisableDisconnectedNtfns(true))
+	}
+	return w.watchRescan.Update(options...)
+}
+
+// blockBefore returns the height of the block before startHeight, which is
+// the start block of a neutrino rescan that matches blocks from startHeight,
+// or 0 for the genesis block.
+func blockBefore(startHeight int32) int32 {
+	if startHeight > 0 {
+		return startHeight - 1
+	}
+	return 0
+}
+
+// onWatchedBlockDisconnected records that the disconnected block must be
+// matched again when the watch rescan is restarted.
+func (w *Wallet[_]) onWatchedBlockDisconnected(height int32, _ *wire.BlockHeader) {
+	w.watchScanMtx.Lock()
+	if w.watchScanned && w.watchScanHeight >= height {
+		w.watchScanHeight = blockBefore(height)
+	}
+	w.watchScanMtx.Unlock()
+}
+
+// onWatchedBlockConnected emits an asset.WatchMatchEvent for each transaction
+// in the block that pays to a watched script or spends a watched outpoint.
+func (w *Wallet[_]) onWatchedBlockConnected(height int32, header *wire.BlockHeader, txs []*ltcutil.Tx) {
+	watchList := w.WatchList()
+	block := asset.BlockInfo{
+		Height:    height,
+		Hash:      header.BlockHash().String(),
+		Timestamp: header.Timestamp,
+	}
+	for _, tx := range txs {
+		msgTx := tx.MsgTx()
+		match := &asset.WatchMatchEvent{
+			TxHash: tx.Hash().String(),
+			Block:  block,
+		}
+		for i, txOut := range msgTx.TxOut {
+			if watchList.IsWatchedScript(txOut.PkScript) {
+				match.Outputs = append(match.Outputs, uint32(i))
+			}
+		}
+		for _, txIn := range msgTx.TxIn {
+			if outpoint := txIn.PreviousOutPoint.String(); watchList.IsWatchedOutpoint(outpoint) {
+				match.SpentOutpoints = append(match.SpentOutpoints, outpoint)
+			}
+		}
+		if len(match.Outputs) == 0 && len(match.SpentOutpoints) == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := msgTx.Serialize(&buf); err != nil {
+			w.log.Errorf("error serializing watched tx %s: %v", match.TxHash, err)
+			continue
+		}
+		match.Tx = buf.Bytes()
+		w.NotifyWatchMatch(match)
+	}
+
+	w.watchScanMtx.Lock()
+	w.watchScanHeight, w.watchScanned = height, true
+	w.watchScanMtx.Unlock()
+}
+
+// scriptAddress returns the address that pkScript pays to. An error is
+// returned if pkScript does not pay to exactly one address.
+func scriptAddress(pkScript []byte, chainParams *chaincfg.Params) (ltcutil.Address, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script %x: %w", pkScript, err)
+	}
+	if len(addrs) != 1 {
+		return nil, fmt.Errorf("script %x does not pay to a single address", pkScript)
+	}
+	return addrs[0], nil
+}
+
+// parseOutPoint parses an outpoint from its string representation.
+func parseOutPoint(outpoint string) (*wire.OutPoint, error) {
+	hashStr, indexStr, found := strings.Cut(outpoint, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid outpoint %s", outpoint)
+	}
+	hash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		return nil, err
+	}
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewOutPoint(hash, uint32(index)), nil
+}
@@ -5,18 +5,23 @@ import (
 	"strings"
 )
 
-// Network flags passed to asset backends to signify which network to use.
+// Network flags passed to asset backends to signify which network to use. Not
+// all assets support every network, asset backends return an error wrapping
+// ErrUnsupportedNetwork for networks they do not support.
 type Network uint8
 
+// The numeric values of networks are persisted and passed across language
+// boundaries by apps, so they must never change. New networks are appended.
 const (
-	Mainnet Network = iota
-	Testnet
-	Regtest
+	Mainnet Network = 0
+	Testnet Network = 1
+	Regtest Network = 2
+	Signet  Network = 3
+	// Simnet was an alias of Regtest before it became a distinct network. See
+	// dcr.MigrateLegacyNetwork for dcr simnet values persisted as Regtest.
+	Simnet Network = 4
 )
 
-// Simnet is an alias of Regtest.
-const Simnet = Regtest
-
 // String returns the string representation of a Network.
 func (n Network) String() string {
 	switch n {
@@ -24,8 +29,12 @@ func (n Network) String() string {
 		return "mainnet"
 	case Testnet:
 		return "testnet"
+	case Regtest:
+		return "regtest"
 	case Simnet:
 		return "simnet"
+	case Signet:
+		return "signet"
 	}
 	return ""
 }
@@ -37,8 +46,24 @@ func NetFromString(net string) (Network, error) {
 		return Mainnet, nil
 	case "testnet":
 		return Testnet, nil
-	case "regtest", "regnet", "simnet":
+	case "regtest", "regnet":
+		return Regtest, nil
+	case "simnet":
 		return Simnet, nil
+	case "signet":
+		return Signet, nil
 	}
 	return 255, fmt.Errorf("unknown network %s", net)
 }
+
+// SignetConfig describes a custom signet network. Signet networks are test
+// networks where valid blocks must be signed by the signers specified in the
+// network's challenge script.
+type SignetConfig struct {
+	// Challenge is the signet challenge script. The default public signet is
+	// used if no challenge is provided.
+	Challenge []byte
+	// SeedNodes are the addresses of nodes to connect to when syncing with
+	// the signet network.
+	SeedNodes []string
+}
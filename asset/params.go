@@ -16,6 +16,40 @@ type OpenWalletParams[Tx any] struct {
 	UserConfigDB   walletdata.UserConfigDB
 	WalletConfigDB walletdata.WalletConfigDB
 
+	// ChainName, if set, is the name of the custom chain params to use in
+	// place of the built-in chain params for Net. The custom chain params must
+	// have been registered with the asset backend.
+	ChainName string
+
+	// Signet is only used if Net is Signet and the asset supports signet. Can
+	// be nil to use the default public signet.
+	Signet *SignetConfig
+
+	// Proxy, if set, is used for all SPV peer connections and peer address
+	// resolution.
+	Proxy *ProxyConfig
+
+	// StorePeersInWalletDB causes the peers added by the user and peer bans
+	// to be stored in WalletConfigDB instead of files in DataDir.
+	StorePeersInWalletDB bool
+
+	// PeerResolver, if set, is used to resolve the host names of peers added
+	// via StartSync or AddPeer. Otherwise, host names are resolved through
+	// Proxy if set, or using the system resolver.
+	PeerResolver Resolver
+	// PreferIPv6 causes IPv6 addresses to be used for peers whose host names
+	// resolve to both IPv4 and IPv6 addresses.
+	PreferIPv6 bool
+
+	// SyncSupervisor, if set, configures the supervisor that restarts stalled
+	// syncs for assets that use one. Defaults are used if nil.
+	SyncSupervisor *SyncSupervisorConfig
+
+	// StopChainServiceOnSyncEnd causes SPV wallets that keep their chain
+	// service running between syncs to stop it when sync ends, closing all
+	// peer connections. The chain service is rebuilt when sync is restarted.
+	StopChainServiceOnSyncEnd bool
+
 	// TxIndexDB is only required if transaction indexing is desired. Can be nil
 	// otherwise.
 	TxIndexDB walletdata.TxIndexDB[Tx]
@@ -0,0 +1,65 @@
+package asset
+
+import (
+	"fmt"
+	"net"
+)
+
+// PeerConnectionMode determines how an SPV wallet selects the peers that it
+// connects to when syncing.
+type PeerConnectionMode uint8
+
+const (
+	// PeerModeDiscover connects to peers discovered via DNS seeds and the
+	// address manager, as well as peers previously added with AddPeer. No
+	// peers may be specified in this mode.
+	PeerModeDiscover PeerConnectionMode = iota
+	// PeerModeAddNodes connects to the specified peers in addition to
+	// discovered peers and peers previously added with AddPeer.
+	PeerModeAddNodes
+	// PeerModeConnectOnly connects exclusively to the specified peers. Peer
+	// discovery is disabled and peers previously added with AddPeer are not
+	// connected to.
+	PeerModeConnectOnly
+)
+
+// String returns a human-readable representation of the connection mode.
+func (m PeerConnectionMode) String() string {
+	switch m {
+	case PeerModeDiscover:
+		return "discover"
+	case PeerModeAddNodes:
+		return "addnodes"
+	case PeerModeConnectOnly:
+		return "connectonly"
+	default:
+		return fmt.Sprintf("unknown peer connection mode %d", uint8(m))
+	}
+}
+
+// ValidatePeers checks that the specified peers can be used with the
+// connection mode.
+func (m PeerConnectionMode) ValidatePeers(peers []string) error {
+	switch m {
+	case PeerModeDiscover:
+		if len(peers) > 0 {
+			return fmt.Errorf("peers cannot be specified in %s mode", m)
+		}
+	case PeerModeAddNodes, PeerModeConnectOnly:
+		if len(peers) == 0 {
+			return fmt.Errorf("at least one peer must be specified in %s mode", m)
+		}
+	default:
+		return fmt.Errorf("%s", m)
+	}
+	return nil
+}
+
+// WithDefaultPort returns addr with defaultPort appended if addr does not
+// specify a port.
+func WithDefaultPort(addr, defaultPort string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(addr, defaultPort)
+	}
+	return addr
+}
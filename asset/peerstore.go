@@ -0,0 +1,368 @@
+package asset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/decred/slog"
+	"github.com/itswisdomagain/libwallet/walletdata"
+)
+
+const (
+	savedPeersDBKey  = "savedPeers"
+	bannedPeersDBKey = "bannedPeers"
+)
+
+// PeerStore persists the peers added by the user and the peers banned by an
+// SPVPeerManager.
+type PeerStore interface {
+	// SavedPeers returns the peers added by the user.
+	SavedPeers() (map[string]PeerSource, error)
+	// UpdateSavedPeers reads the peers added by the user, calls update to
+	// modify them and persists the result, atomically.
+	UpdateSavedPeers(update func(peers map[string]PeerSource)) error
+	// BannedPeers returns the banned peers, keyed by resolved address.
+	BannedPeers() (map[string]*BannedPeer, error)
+	// SaveBannedPeers replaces the persisted banned peers.
+	SaveBannedPeers(bans map[string]*BannedPeer) error
+}
+
+// validSavedPeers deletes invalid entries from peers and returns false if any
+// entry was deleted.
+func validSavedPeers(peers map[string]PeerSource) bool {
+	valid := true
+	for addr, source := range peers {
+		if addr == "" || source > DiscoveredPeer {
+			delete(peers, addr)
+			valid = false
+		}
+	}
+	return valid
+}
+
+// validBannedPeers deletes invalid entries from bans and returns false if any
+// entry was deleted.
+func validBannedPeers(bans map[string]*BannedPeer) bool {
+	valid := true
+	for addr, ban := range bans {
+		if addr == "" || ban == nil || ban.Until.IsZero() {
+			delete(bans, addr)
+			valid = false
+		}
+	}
+	return valid
+}
+
+const (
+	// peerFileLockTimeout is how long to wait for another process to release
+	// the lock on a peers file.
+	peerFileLockTimeout = 10 * time.Second
+	// stalePeerFileLockAge is the age after which a peers file lock is assumed
+	// to have been left behind by a process that exited without releasing it.
+	// Peers files are small, so no process holds the lock for this long.
+	stalePeerFileLockAge = 30 * time.Second
+)
+
+// peerFileLocks holds a mutex for every peers file in use by this process, so
+// that wallets that share a peers file do not overwrite each other's changes.
+var peerFileLocks sync.Map
+
+func peerFileLock(path string) *sync.Mutex {
+	mtx, _ := peerFileLocks.LoadOrStore(filepath.Clean(path), new(sync.Mutex))
+	return mtx.(*sync.Mutex)
+}
+
+// lockPeerFile locks the peers file at path against concurrent access by this
+// process and by other processes, and returns a function that releases the
+// lock. Other processes are excluded with a lock file next to path, which is
+// created exclusively and removed on unlock.
+func lockPeerFile(path string) (unlock func(), err error) {
+	mtx := peerFileLock(path)
+	mtx.Lock()
+
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(peerFileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(lockPath)
+				mtx.Unlock()
+			}, nil
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			// The directory does not exist, so neither does the peers file.
+			// Writes to the file will fail without the lock.
+			return mtx.Unlock, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			mtx.Unlock()
+			return nil, fmt.Errorf("error locking %s: %w", path, err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > stalePeerFileLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			mtx.Unlock()
+			return nil, fmt.Errorf("timed out waiting for lock file %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// filePeerStore is a PeerStore that persists peers to JSON files. Files are
+// replaced atomically and locked while they are read or modified, and corrupt
+// files are moved aside when they are read.
+type filePeerStore struct {
+	savedPeersFilePath  string
+	bannedPeersFilePath string
+	log                 slog.Logger
+}
+
+// NewFilePeerStore returns a PeerStore that persists the peers added by the
+// user to savedPeersFilePath and peer bans to BannedPeersFileName in the same
+// directory.
+func NewFilePeerStore(savedPeersFilePath string, log slog.Logger) PeerStore {
+	return &filePeerStore{
+		savedPeersFilePath:  savedPeersFilePath,
+		bannedPeersFilePath: filepath.Join(filepath.Dir(savedPeersFilePath), BannedPeersFileName),
+		log:                 log,
+	}
+}
+
+// PeerStoreFiles returns the file name patterns of the files created by the
+// file PeerStore for savedPeersFileName: the saved and banned peers files and
+// the lock, temporary and corrupt files created alongside them.
+func PeerStoreFiles(savedPeersFileName string) []string {
+	var files []string
+	for _, name := range []string{savedPeersFileName, BannedPeersFileName} {
+		files = append(files, name, name+".lock", name+".*.tmp", name+".corrupt")
+	}
+	return files
+}
+
+// readJSONFile reads the JSON-encoded contents of path into out. A missing file
+// is not an error. A file that cannot be decoded is renamed with a .corrupt
+// suffix for inspection and read as empty, rather than causing every
+// subsequent read to fail.
+func (s *filePeerStore) readJSONFile(path string, out any) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(content, out); err != nil {
+		corruptPath := path + ".corrupt"
+		s.log.Errorf("Peers file %s is corrupt and will be moved to %s: %v", path, corruptPath, err)
+		return os.Rename(path, corruptPath)
+	}
+	return nil
+}
+
+func (s *filePeerStore) readSavedPeers() (map[string]PeerSource, error) {
+	peers := make(map[string]PeerSource)
+	if err := s.readJSONFile(s.savedPeersFilePath, &peers); err != nil {
+		return nil, err
+	}
+	if peers == nil { // file contained null
+		peers = make(map[string]PeerSource)
+	}
+	if !validSavedPeers(peers) {
+		s.log.Warnf("Removing invalid entries from saved peers file %s", s.savedPeersFilePath)
+		if err := s.writeSavedPeers(peers); err != nil {
+			return nil, err
+		}
+	}
+	return peers, nil
+}
+
+func (s *filePeerStore) writeSavedPeers(peers map[string]PeerSource) error {
+	content, err := json.Marshal(peers)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.savedPeersFilePath, content)
+}
+
+// SavedPeers returns the peers added by the user.
+func (s *filePeerStore) SavedPeers() (map[string]PeerSource, error) {
+	unlock, err := lockPeerFile(s.savedPeersFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.readSavedPeers()
+}
+
+// UpdateSavedPeers reads the peers added by the user, calls update to modify
+// them and persists the result. Wallets in this or other processes that share
+// the saved peers file cannot modify it concurrently.
+func (s *filePeerStore) UpdateSavedPeers(update func(peers map[string]PeerSource)) error {
+	unlock, err := lockPeerFile(s.savedPeersFilePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	peers, err := s.readSavedPeers()
+	if err != nil {
+		return err
+	}
+	update(peers)
+	return s.writeSavedPeers(peers)
+}
+
+// BannedPeers returns the banned peers, keyed by resolved address.
+func (s *filePeerStore) BannedPeers() (map[string]*BannedPeer, error) {
+	unlock, err := lockPeerFile(s.bannedPeersFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	bans := make(map[string]*BannedPeer)
+	if err := s.readJSONFile(s.bannedPeersFilePath, &bans); err != nil {
+		return nil, err
+	}
+	if bans == nil { // file contained null
+		bans = make(map[string]*BannedPeer)
+	}
+	validBannedPeers(bans)
+	return bans, nil
+}
+
+// SaveBannedPeers replaces the persisted banned peers.
+func (s *filePeerStore) SaveBannedPeers(bans map[string]*BannedPeer) error {
+	unlock, err := lockPeerFile(s.bannedPeersFilePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := json.Marshal(bans)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.bannedPeersFilePath, content)
+}
+
+// writeFileAtomic replaces the contents of path by writing content to a
+// temporary file in the same directory, syncing it to disk and renaming it to
+// path, so that path is never left partially written.
+func writeFileAtomic(path string, content []byte) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmpFile, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // no-op if the file is renamed
+
+	if _, err = tmpFile.Write(content); err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", tmpPath, err)
+	}
+	if err = os.Chmod(tmpPath, 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Sync the directory to persist the rename. Not all platforms support
+	// syncing directories, so errors are ignored.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// walletDBPeerStore is a PeerStore that persists peers to a wallet's config db.
+type walletDBPeerStore struct {
+	mtx sync.Mutex
+	db  walletdata.WalletConfigDB
+}
+
+// NewWalletDBPeerStore returns a PeerStore that persists peers to the provided
+// wallet config db.
+func NewWalletDBPeerStore(db walletdata.WalletConfigDB) PeerStore {
+	return &walletDBPeerStore{db: db}
+}
+
+func (s *walletDBPeerStore) readSavedPeers() (map[string]PeerSource, error) {
+	peers := make(map[string]PeerSource)
+	err := s.db.ReadWalletConfigValue(savedPeersDBKey, &peers)
+	if err != nil && !errors.Is(err, walletdata.ErrNotFound) {
+		return nil, err
+	}
+	if peers == nil {
+		peers = make(map[string]PeerSource)
+	}
+	validSavedPeers(peers)
+	return peers, nil
+}
+
+// SavedPeers returns the peers added by the user.
+func (s *walletDBPeerStore) SavedPeers() (map[string]PeerSource, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.readSavedPeers()
+}
+
+// UpdateSavedPeers reads the peers added by the user, calls update to modify
+// them and persists the result.
+func (s *walletDBPeerStore) UpdateSavedPeers(update func(peers map[string]PeerSource)) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	peers, err := s.readSavedPeers()
+	if err != nil {
+		return err
+	}
+	update(peers)
+	return s.db.SaveWalletConfigValue(savedPeersDBKey, peers)
+}
+
+// BannedPeers returns the banned peers, keyed by resolved address.
+func (s *walletDBPeerStore) BannedPeers() (map[string]*BannedPeer, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	bans := make(map[string]*BannedPeer)
+	err := s.db.ReadWalletConfigValue(bannedPeersDBKey, &bans)
+	if err != nil && !errors.Is(err, walletdata.ErrNotFound) {
+		return nil, err
+	}
+	if bans == nil {
+		bans = make(map[string]*BannedPeer)
+	}
+	validBannedPeers(bans)
+	return bans, nil
+}
+
+// SaveBannedPeers replaces the persisted banned peers.
+func (s *walletDBPeerStore) SaveBannedPeers(bans map[string]*BannedPeer) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.db.SaveWalletConfigValue(bannedPeersDBKey, bans)
+}
@@ -0,0 +1,72 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/decred/dcrd/connmgr/v3"
+	"github.com/decred/go-socks/socks"
+)
+
+// ProxyConfig configures a SOCKS5 proxy, such as Tor, through which SPV peer
+// connections are made. Peer addresses are also resolved through the proxy
+// using Tor's RESOLVE extension, so that DNS lookups do not leak.
+type ProxyConfig struct {
+	// Addr is the host:port of the SOCKS5 proxy.
+	Addr     string
+	Username string
+	Password string
+
+	// StreamIsolation causes random credentials to be used for every
+	// connection, so that Tor uses a separate circuit for each peer. Username
+	// and Password are ignored if set.
+	StreamIsolation bool
+
+	// OnionOnly restricts peer connections to .onion addresses.
+	OnionOnly bool
+}
+
+// Dial connects to addr through the proxy. If OnionOnly is set, an error is
+// returned if addr is not an onion address.
+func (p *ProxyConfig) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if p.Addr == "" {
+		return nil, fmt.Errorf("proxy address not set")
+	}
+	if p.OnionOnly && !IsOnionAddr(addr) {
+		return nil, fmt.Errorf("refusing to connect to non-onion address %s in onion-only mode", addr)
+	}
+
+	proxy := &socks.Proxy{
+		Addr:         p.Addr,
+		Username:     p.Username,
+		Password:     p.Password,
+		TorIsolation: p.StreamIsolation,
+	}
+	return proxy.DialContext(ctx, network, addr)
+}
+
+// LookupIP resolves host through the proxy. The proxy must support Tor's
+// RESOLVE extension.
+func (p *ProxyConfig) LookupIP(host string) ([]net.IP, error) {
+	return p.LookupIPContext(context.Background(), host)
+}
+
+// LookupIPContext is like LookupIP but uses the provided ctx.
+func (p *ProxyConfig) LookupIPContext(ctx context.Context, host string) ([]net.IP, error) {
+	if p.Addr == "" {
+		return nil, fmt.Errorf("proxy address not set")
+	}
+	return connmgr.TorLookupIP(ctx, host, p.Addr)
+}
+
+// IsOnionAddr returns true if addr, which may include a port, is a Tor onion
+// address.
+func IsOnionAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return strings.HasSuffix(host, ".onion")
+}
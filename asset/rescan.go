@@ -0,0 +1,11 @@
+package asset
+
+// RescanProgress describes the progress of a wallet rescan.
+type RescanProgress struct {
+	StartHeight    int32
+	ScannedThrough int32
+	EndHeight      int32
+}
+
+// RescanProgressListener is notified of the progress of a wallet rescan.
+type RescanProgressListener func(progress *RescanProgress)
@@ -0,0 +1,61 @@
+package asset
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultResolveTimeout is the maximum duration that SPVPeerManager waits
+	// for a peer's host name to be resolved.
+	DefaultResolveTimeout = 10 * time.Second
+
+	// DefaultPeerReresolveInterval is the interval at which the host names of
+	// peers are re-resolved to detect peers whose IP address changed.
+	DefaultPeerReresolveInterval = 30 * time.Minute
+)
+
+// Resolver resolves host names to IP addresses.
+type Resolver interface {
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as
+// Resolvers.
+type ResolverFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+// LookupIP calls f(ctx, host).
+func (f ResolverFunc) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return f(ctx, host)
+}
+
+// SystemResolver resolves host names using the system's resolver.
+var SystemResolver Resolver = ResolverFunc(func(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+})
+
+// selectIP picks the IP address to use for host from ips, preferring IPv6
+// addresses if preferIPv6 is true and IPv4 addresses otherwise. ips must not
+// be empty.
+func selectIP(host string, ips []net.IP, preferIPv6 bool) net.IP {
+	if host == "localhost" && !preferIPv6 && len(ips) > 1 {
+		return net.IPv4(127, 0, 0, 1)
+	}
+	for _, ip := range ips {
+		if isIPv6 := ip.To4() == nil; isIPv6 == preferIPv6 {
+			return ip
+		}
+	}
+	return ips[0]
+}
+
+// isIPOrOnionAddr returns true if addr, which may include a port, is an IP
+// address or an onion address, neither of which needs to be resolved.
+func isIPOrOnionAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host) != nil || IsOnionAddr(host)
+}
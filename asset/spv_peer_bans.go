@@ -0,0 +1,228 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// BannedPeersFileName is the name of the file that the file PeerStore uses to
+// persist peer bans. The file is created in the same directory as the saved
+// peers file.
+const BannedPeersFileName = "banned-peers.json"
+
+// DefaultPeerBanDuration is the duration for which peers reported as
+// misbehaving are banned.
+const DefaultPeerBanDuration = 24 * time.Hour
+
+// BannedPeer provides information about a banned peer.
+type BannedPeer struct {
+	Addr   string    `json:"addr"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+// peerBanChecker is implemented by chain services that ban misbehaving peers
+// on their own, such as neutrino which bans peers that serve invalid headers
+// or filters.
+type peerBanChecker interface {
+	IsBanned(addr string) bool
+}
+
+// isBanned returns true if the peer with the provided resolved address is
+// banned by the peer manager or by the chain service. The peersMtx must be
+// held, at least for reads.
+func (s *SPVPeerManager) isBanned(resolvedAddr string) bool {
+	if ban, found := s.bans[resolvedAddr]; found && time.Now().Before(ban.Until) {
+		return true
+	}
+	if checker, ok := s.cs.(peerBanChecker); ok {
+		return checker.IsBanned(resolvedAddr)
+	}
+	return false
+}
+
+// CheckPeerBan returns an error wrapping ErrPeerBanned if the peer with the
+// provided resolved address is banned in store.
+func CheckPeerBan(store PeerStore, resolvedAddr string) error {
+	bans, err := store.BannedPeers()
+	if err != nil {
+		return fmt.Errorf("error reading banned peers: %w", err)
+	}
+	if ban, found := bans[resolvedAddr]; found && time.Now().Before(ban.Until) {
+		return fmt.Errorf("%w: %s", ErrPeerBanned, resolvedAddr)
+	}
+	return nil
+}
+
+// PeerBanDialer wraps dial so that it refuses to connect to the peers banned in
+// store. Chain services that discover peers or reconnect to peers on their own
+// must dial peers through it for bans to be enforced, because disconnecting a
+// banned peer does not prevent the chain service from redialing it. dial may
+// be nil to use net.Dial.
+func PeerBanDialer(store PeerStore, dial func(addr net.Addr) (net.Conn, error)) func(addr net.Addr) (net.Conn, error) {
+	if dial == nil {
+		dial = func(addr net.Addr) (net.Conn, error) {
+			return net.Dial(addr.Network(), addr.String())
+		}
+	}
+	return func(addr net.Addr) (net.Conn, error) {
+		if err := CheckPeerBan(store, addr.String()); err != nil {
+			return nil, err
+		}
+		return dial(addr)
+	}
+}
+
+// PeerBanContextDialer is like PeerBanDialer, for chain services that dial
+// peers with a context and a network name. dial may be nil to use a
+// net.Dialer.
+func PeerBanContextDialer(store PeerStore, dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = new(net.Dialer).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if err := CheckPeerBan(store, addr); err != nil {
+			return nil, err
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// BanPeer disconnects from the peer at addr and prevents the wallet from
+// connecting to it again for the specified duration.
+func (s *SPVPeerManager) BanPeer(addr string, duration time.Duration, reason string) error {
+	if duration <= 0 {
+		return fmt.Errorf("invalid ban duration: %v", duration)
+	}
+
+	resolvedAddr, err := s.resolveAddress(context.Background(), addr)
+	if err != nil {
+		return fmt.Errorf("failed to resolve address: %v", err)
+	}
+
+	s.peersMtx.Lock()
+	defer s.peersMtx.Unlock()
+	return s.banPeer(addr, resolvedAddr, duration, reason)
+}
+
+// ReportMisbehavingPeer bans the peer with the provided resolved address for
+// DefaultPeerBanDuration. This is used by the wallet backends to ban peers
+// that send invalid data such as invalid headers or filters.
+func (s *SPVPeerManager) ReportMisbehavingPeer(resolvedAddr, reason string) {
+	s.peersMtx.Lock()
+	defer s.peersMtx.Unlock()
+
+	addr := resolvedAddr
+	if originalAddr, found := s.peerWithResolvedAddr(resolvedAddr); found {
+		addr = originalAddr
+	}
+
+	s.log.Warnf("Banning misbehaving peer %s: %s", addr, reason)
+	if err := s.banPeer(addr, resolvedAddr, DefaultPeerBanDuration, reason); err != nil {
+		s.log.Errorf("failed to ban peer %s: %v", addr, err)
+	}
+}
+
+// banPeer records the ban, persists it and disconnects from the peer. Chain
+// services that dial peers through PeerBanDialer refuse to reconnect to the
+// peer until the ban expires. The peersMtx must be held.
+func (s *SPVPeerManager) banPeer(addr, resolvedAddr string, duration time.Duration, reason string) error {
+	s.pruneExpiredBans()
+	s.bans[resolvedAddr] = &BannedPeer{
+		Addr:   addr,
+		Until:  time.Now().Add(duration),
+		Reason: reason,
+	}
+	if err := s.store.SaveBannedPeers(s.bans); err != nil {
+		s.log.Errorf("failed to save banned peers: %v", err)
+	}
+
+	connectedPeers := s.connectedPeers()
+	if _, connected := connectedPeers[resolvedAddr]; connected {
+		return s.cs.RemoveNodeByAddr(resolvedAddr)
+	}
+
+	// Also remove peers added by the user that are not currently connected,
+	// to prevent the chain service from reconnecting to them.
+	if _, found := s.peerWithResolvedAddr(resolvedAddr); found {
+		if err := s.cs.RemoveNodeByAddr(resolvedAddr); err != nil {
+			s.log.Debugf("unable to remove banned peer %s: %v", addr, err)
+		}
+	}
+
+	return nil
+}
+
+// UnbanPeer lifts a ban previously placed on the peer at addr. If the peer
+// was added by the user, the wallet reconnects to it.
+func (s *SPVPeerManager) UnbanPeer(addr string) error {
+	s.peersMtx.RLock()
+	resolvedAddr := ""
+	for bannedAddr, ban := range s.bans {
+		if ban.Addr == addr || bannedAddr == addr {
+			resolvedAddr = bannedAddr
+			break
+		}
+	}
+	s.peersMtx.RUnlock()
+
+	if resolvedAddr == "" {
+		var err error
+		resolvedAddr, err = s.resolveAddress(context.Background(), addr)
+		if err != nil {
+			return fmt.Errorf("failed to resolve address: %v", err)
+		}
+	}
+
+	s.peersMtx.Lock()
+	defer s.peersMtx.Unlock()
+
+	if _, found := s.bans[resolvedAddr]; !found {
+		return fmt.Errorf("peer not banned: %v", addr)
+	}
+
+	delete(s.bans, resolvedAddr)
+	s.pruneExpiredBans()
+	if err := s.store.SaveBannedPeers(s.bans); err != nil {
+		s.log.Errorf("failed to save banned peers: %v", err)
+	}
+
+	if _, found := s.peerWithResolvedAddr(resolvedAddr); found {
+		return s.cs.ConnectNode(resolvedAddr, true)
+	}
+
+	return nil
+}
+
+// BannedPeers returns the peers that are currently banned by the peer manager,
+// sorted by address.
+func (s *SPVPeerManager) BannedPeers() []*BannedPeer {
+	s.peersMtx.RLock()
+	defer s.peersMtx.RUnlock()
+
+	now := time.Now()
+	bannedPeers := make([]*BannedPeer, 0, len(s.bans))
+	for _, ban := range s.bans {
+		if now.Before(ban.Until) {
+			banCopy := *ban
+			bannedPeers = append(bannedPeers, &banCopy)
+		}
+	}
+	sort.Slice(bannedPeers, func(i, j int) bool {
+		return bannedPeers[i].Addr < bannedPeers[j].Addr
+	})
+	return bannedPeers
+}
+
+// pruneExpiredBans removes expired bans. The peersMtx must be held.
+func (s *SPVPeerManager) pruneExpiredBans() {
+	now := time.Now()
+	for addr, ban := range s.bans {
+		if !now.Before(ban.Until) {
+			delete(s.bans, addr)
+		}
+	}
+}
@@ -1,13 +1,12 @@
 package asset
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"net"
-	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/decred/slog"
 )
@@ -23,6 +22,9 @@ const (
 type walletPeer struct {
 	source       PeerSource
 	resolvedName string
+	// connect is false for peers that the chain service connects to on its
+	// own, which are only tracked by the peer manager.
+	connect bool
 }
 
 // WalletPeer provides information about a wallet's peer.
@@ -30,14 +32,35 @@ type WalletPeer struct {
 	Addr      string     `json:"addr"`
 	Source    PeerSource `json:"source"`
 	Connected bool       `json:"connected"`
+	Banned    bool       `json:"banned"`
+	// Stats is only set if the wallet is connected to the peer.
+	Stats *PeerStats `json:"stats,omitempty"`
 }
 
-// SPVPeer is satisfied by *neutrino.ServerPeer, but is generalized to
-// accommodate underlying implementations other than lightninglabs/neutrino.
+// PeerStats provides details and statistics about a connected peer. Fields
+// that are not tracked by the underlying SPV implementation are left empty.
+type PeerStats struct {
+	UserAgent       string        `json:"userAgent"`
+	ProtocolVersion uint32        `json:"protocolVersion"`
+	Services        string        `json:"services"`
+	Inbound         bool          `json:"inbound"`
+	PingTime        time.Duration `json:"pingTime"`
+	BytesSent       uint64        `json:"bytesSent"`
+	BytesReceived   uint64        `json:"bytesReceived"`
+	ConnectedAt     time.Time     `json:"connectedAt"`
+	StartingHeight  int32         `json:"startingHeight"`
+	BestHeight      int32         `json:"bestHeight"`
+	BanScore        uint32        `json:"banScore"`
+}
+
+// SPVPeer is satisfied by wrappers around *neutrino.ServerPeer, but is
+// generalized to accommodate underlying implementations other than
+// lightninglabs/neutrino.
 type SPVPeer interface {
 	StartingHeight() int32
 	LastBlock() int32
 	Addr() string
+	Stats() *PeerStats
 }
 
 // PeerManagerChainService are the functions needed for an SPVPeerManager
@@ -55,33 +78,74 @@ type SPVPeerManager struct {
 
 	peersMtx sync.RWMutex
 	peers    map[string]*walletPeer
+	// bans maps the resolved addresses of banned peers to their ban info.
+	bans map[string]*BannedPeer
 
-	savedPeersFilePath string
+	store PeerStore
 
 	defaultPeers []string
 
 	defaultPort string
 
+	// proxy, if set, restricts peers to onion addresses in onion-only mode.
+	proxy *ProxyConfig
+
+	resolver       Resolver
+	resolveTimeout time.Duration
+	preferIPv6     bool
+
 	log slog.Logger
 }
 
-// NewSPVPeerManager creates a new SPVPeerManager.
-func NewSPVPeerManager(cs PeerManagerChainService, defaultPeers []string, savedPeersFilePath string, log slog.Logger, defaultPort string) *SPVPeerManager {
-	return &SPVPeerManager{
-		cs:                 cs,
-		defaultPeers:       defaultPeers,
-		peers:              make(map[string]*walletPeer),
-		savedPeersFilePath: savedPeersFilePath,
-		log:                log,
-		defaultPort:        defaultPort,
+// NewSPVPeerManager creates a new SPVPeerManager. The peers added by the user
+// and peer bans are persisted to store. If proxy is not nil, peer addresses
+// are resolved through the proxy unless a different resolver is set with
+// SetResolver.
+func NewSPVPeerManager(cs PeerManagerChainService, defaultPeers []string, store PeerStore, log slog.Logger, defaultPort string, proxy *ProxyConfig) *SPVPeerManager {
+	s := &SPVPeerManager{
+		cs:             cs,
+		defaultPeers:   defaultPeers,
+		peers:          make(map[string]*walletPeer),
+		store:          store,
+		log:            log,
+		defaultPort:    defaultPort,
+		proxy:          proxy,
+		resolver:       SystemResolver,
+		resolveTimeout: DefaultResolveTimeout,
+	}
+	if proxy != nil {
+		s.resolver = ResolverFunc(proxy.LookupIPContext)
+	}
+
+	bans, err := store.BannedPeers()
+	if err != nil {
+		log.Errorf("failed to load banned peers: %v", err)
+		bans = make(map[string]*BannedPeer)
+	}
+	s.bans = bans
+
+	return s
+}
+
+// SetResolver sets the resolver used to resolve peer host names and the
+// maximum duration of each lookup. If preferIPv6 is true, IPv6 addresses are
+// used for peers that resolve to both IPv4 and IPv6 addresses. This must be
+// called before any peers are added.
+func (s *SPVPeerManager) SetResolver(resolver Resolver, timeout time.Duration, preferIPv6 bool) {
+	if resolver != nil {
+		s.resolver = resolver
+	}
+	if timeout > 0 {
+		s.resolveTimeout = timeout
 	}
+	s.preferIPv6 = preferIPv6
 }
 
-func (s *SPVPeerManager) connectedPeers() map[string]struct{} {
+func (s *SPVPeerManager) connectedPeers() map[string]SPVPeer {
 	peers := s.cs.Peers()
-	connectedPeers := make(map[string]struct{}, len(peers))
+	connectedPeers := make(map[string]SPVPeer, len(peers))
 	for _, peer := range peers {
-		connectedPeers[peer.Addr()] = struct{}{}
+		connectedPeers[peer.Addr()] = peer
 	}
 	return connectedPeers
 }
@@ -98,20 +162,25 @@ func (s *SPVPeerManager) Peers() ([]*WalletPeer, error) {
 	walletPeers := make([]*WalletPeer, 0, len(connectedPeers))
 
 	for originalAddr, peer := range s.peers {
-		_, connected := connectedPeers[peer.resolvedName]
+		walletPeer := &WalletPeer{
+			Addr:   originalAddr,
+			Source: peer.source,
+			Banned: peer.resolvedName != "" && s.isBanned(peer.resolvedName),
+		}
+		if connectedPeer, connected := connectedPeers[peer.resolvedName]; connected {
+			walletPeer.Connected = true
+			walletPeer.Stats = connectedPeer.Stats()
+		}
 		delete(connectedPeers, peer.resolvedName)
-		walletPeers = append(walletPeers, &WalletPeer{
-			Addr:      originalAddr,
-			Connected: connected,
-			Source:    peer.source,
-		})
+		walletPeers = append(walletPeers, walletPeer)
 	}
 
-	for peer := range connectedPeers {
+	for addr, peer := range connectedPeers {
 		walletPeers = append(walletPeers, &WalletPeer{
-			Addr:      peer,
+			Addr:      addr,
 			Connected: true,
 			Source:    DiscoveredPeer,
+			Stats:     peer.Stats(),
 		})
 	}
 
@@ -123,8 +192,9 @@ func (s *SPVPeerManager) Peers() ([]*WalletPeer, error) {
 // of peers, it will return the resolved addresses. Therefore, we call neutrino
 // with the resolved address, then we keep track of the mapping of address to
 // resolved address in order to be able to display the address the user provided
-// back to the user.
-func (s *SPVPeerManager) resolveAddress(addr string) (string, error) {
+// back to the user. The peersMtx must not be held, so that a slow resolver
+// does not block other callers.
+func (s *SPVPeerManager) resolveAddress(ctx context.Context, addr string) (string, error) {
 	host, strPort, err := net.SplitHostPort(addr)
 	if err != nil {
 		switch err.(type) {
@@ -142,7 +212,18 @@ func (s *SPVPeerManager) resolveAddress(addr string) (string, error) {
 		return addr, nil
 	}
 
-	ips, err := net.LookupIP(host)
+	if s.proxy != nil && s.proxy.OnionOnly {
+		return "", fmt.Errorf("%s is not an onion address", addr)
+	}
+
+	// IP addresses need not be resolved.
+	if ip := net.ParseIP(host); ip != nil {
+		return net.JoinHostPort(ip.String(), strPort), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.resolveTimeout)
+	defer cancel()
+	ips, err := s.resolver.LookupIP(ctx, host)
 	if err != nil {
 		return "", err
 	}
@@ -150,14 +231,8 @@ func (s *SPVPeerManager) resolveAddress(addr string) (string, error) {
 		return "", fmt.Errorf("no addresses found for %s", host)
 	}
 
-	var ip string
-	if host == "localhost" && len(ips) > 1 {
-		ip = "127.0.0.1"
-	} else {
-		ip = ips[0].String()
-	}
-
-	return net.JoinHostPort(ip, strPort), nil
+	ip := selectIP(host, ips, s.preferIPv6)
+	return net.JoinHostPort(ip.String(), strPort), nil
 }
 
 // peerWithResolvedAddress checks to see if there is a peer with a resolved
@@ -172,47 +247,21 @@ func (s *SPVPeerManager) peerWithResolvedAddr(resolvedAddr string) (string, bool
 	return "", false
 }
 
-// loadSavedPeersFromFile returns the contents of dexc-peers.json.
-func (s *SPVPeerManager) loadSavedPeersFromFile() (map[string]PeerSource, error) {
-	content, err := os.ReadFile(s.savedPeersFilePath)
-	if errors.Is(err, os.ErrNotExist) {
-		return make(map[string]PeerSource), nil
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	peers := make(map[string]PeerSource)
-	err = json.Unmarshal(content, &peers)
-	if err != nil {
-		return nil, err
-	}
+func (s *SPVPeerManager) addPeer(addr string, source PeerSource, initialLoad, connect bool) error {
+	resolvedAddr, err := s.resolveAddress(context.Background(), addr)
 
-	return peers, nil
-}
-
-// writeSavedPeersToFile replaces the contents of dexc-peers.json.
-func (s *SPVPeerManager) writeSavedPeersToFile(peers map[string]PeerSource) error {
-	content, err := json.Marshal(peers)
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(s.savedPeersFilePath, content, 0644)
-}
-
-func (s *SPVPeerManager) addPeer(addr string, source PeerSource, initialLoad bool) error {
 	s.peersMtx.Lock()
 	defer s.peersMtx.Unlock()
 
-	resolvedAddr, err := s.resolveAddress(addr)
 	if err != nil {
 		if initialLoad {
 			// If this is the initial load, we still want to add peers that are
 			// not able to be connected to the peers map, in order to display them
 			// to the user. If a user previously added a peer that originally connected
 			// but now the address cannot be resolved to an IP, it should be displayed
-			// that the wallet was unable to connect to that peer.
-			s.peers[addr] = &walletPeer{source: source}
+			// that the wallet was unable to connect to that peer. The address
+			// is resolved again by ReresolvePeers.
+			s.peers[addr] = &walletPeer{source: source, connect: connect}
 		}
 		return fmt.Errorf("failed to resolve address: %v", err)
 	}
@@ -221,21 +270,30 @@ func (s *SPVPeerManager) addPeer(addr string, source PeerSource, initialLoad boo
 		return fmt.Errorf("%s and %s resolve to the same node", duplicatePeer, addr)
 	}
 
-	s.peers[addr] = &walletPeer{source: source, resolvedName: resolvedAddr}
+	if s.isBanned(resolvedAddr) {
+		if initialLoad {
+			// Keep track of banned peers that were previously added so they
+			// can be displayed to the user and reconnected if unbanned.
+			s.peers[addr] = &walletPeer{source: source, resolvedName: resolvedAddr, connect: connect}
+		}
+		return fmt.Errorf("%w: %s", ErrPeerBanned, addr)
+	}
+
+	s.peers[addr] = &walletPeer{source: source, resolvedName: resolvedAddr, connect: connect}
 
 	if !initialLoad {
-		savedPeers, err := s.loadSavedPeersFromFile()
-		if err != nil {
-			s.log.Errorf("failed to load saved peers from file")
-		} else {
+		err := s.store.UpdateSavedPeers(func(savedPeers map[string]PeerSource) {
 			savedPeers[addr] = source
-			err = s.writeSavedPeersToFile(savedPeers)
-			if err != nil {
-				s.log.Errorf("failed to add peer to saved peers file: %v")
-			}
+		})
+		if err != nil {
+			s.log.Errorf("failed to add peer to saved peers: %v", err)
 		}
 	}
 
+	if !connect {
+		return nil
+	}
+
 	connectedPeers := s.connectedPeers()
 	_, connected := connectedPeers[resolvedAddr]
 	if !connected {
@@ -247,7 +305,7 @@ func (s *SPVPeerManager) addPeer(addr string, source PeerSource, initialLoad boo
 
 // AddPeer connects to a new peer and stores it in the db.
 func (s *SPVPeerManager) AddPeer(addr string) error {
-	return s.addPeer(addr, AddedPeer, false)
+	return s.addPeer(addr, AddedPeer, false, true)
 }
 
 // RemovePeer disconnects from a peer added by the user and removes it from
@@ -261,14 +319,11 @@ func (s *SPVPeerManager) RemovePeer(addr string) error {
 		return fmt.Errorf("peer not found: %v", addr)
 	}
 
-	savedPeers, err := s.loadSavedPeersFromFile()
+	err := s.store.UpdateSavedPeers(func(savedPeers map[string]PeerSource) {
+		delete(savedPeers, addr)
+	})
 	if err != nil {
-		return err
-	}
-	delete(savedPeers, addr)
-	err = s.writeSavedPeersToFile(savedPeers)
-	if err != nil {
-		s.log.Errorf("failed to delete peer from saved peers file: %v")
+		s.log.Errorf("failed to delete peer from saved peers: %v", err)
 	} else {
 		delete(s.peers, addr)
 	}
@@ -283,25 +338,135 @@ func (s *SPVPeerManager) RemovePeer(addr string) error {
 }
 
 // ConnectToInitialWalletPeers connects to the default peers and the peers
-// that were added by the user and persisted in the db.
+// that were added by the user and persisted in the db. Banned peers are not
+// connected to.
 func (s *SPVPeerManager) ConnectToInitialWalletPeers() {
+	s.AddDefaultPeers(true)
+	s.ConnectToSavedPeers()
+}
+
+// ConnectToSavedPeers connects to the peers that were added by the user and
+// persisted in the db. Banned peers are not connected to.
+func (s *SPVPeerManager) ConnectToSavedPeers() {
+	savedPeers, err := s.store.SavedPeers()
+	if err != nil {
+		s.log.Errorf("failed to load saved peers: %v", err)
+		return
+	}
+
+	for addr := range savedPeers {
+		err := s.addPeer(addr, AddedPeer, true, true)
+		if err != nil {
+			s.log.Errorf("failed to add peer %s: %v", addr, err)
+		}
+	}
+}
+
+// AddDefaultPeers adds the default peers without loading the peers that were
+// added by the user. If connect is false, the default peers are only tracked,
+// for chain services that were configured to connect to them on their own.
+func (s *SPVPeerManager) AddDefaultPeers(connect bool) {
 	for _, peer := range s.defaultPeers {
-		err := s.addPeer(peer, DefaultPeer, true)
+		err := s.addPeer(peer, DefaultPeer, true, connect)
 		if err != nil {
 			s.log.Errorf("failed to add default peer %s: %v", peer, err)
 		}
 	}
+}
 
-	savedPeers, err := s.loadSavedPeersFromFile()
-	if err != nil {
-		s.log.Errorf("failed to load saved peers from file: v", err)
-		return
+// ReconnectPeers connects to the tracked peers that the wallet is not connected
+// to, except banned peers. This is used to recover from the loss of all peers.
+func (s *SPVPeerManager) ReconnectPeers() {
+	s.peersMtx.RLock()
+	defer s.peersMtx.RUnlock()
+
+	connectedPeers := s.connectedPeers()
+	for addr, peer := range s.peers {
+		if !peer.connect || peer.resolvedName == "" || s.isBanned(peer.resolvedName) {
+			continue
+		}
+		if _, connected := connectedPeers[peer.resolvedName]; connected {
+			continue
+		}
+		if err := s.cs.ConnectNode(peer.resolvedName, true); err != nil {
+			s.log.Debugf("failed to reconnect to peer %s: %v", addr, err)
+		}
 	}
+}
 
-	for addr := range savedPeers {
-		err := s.addPeer(addr, AddedPeer, true)
+// ReresolvePeers resolves the host names of the tracked peers again and
+// reconnects to peers whose IP address changed. Peers that could not be
+// resolved previously are connected to if they now resolve.
+func (s *SPVPeerManager) ReresolvePeers(ctx context.Context) {
+	s.peersMtx.RLock()
+	resolvedAddrs := make(map[string]string)
+	for addr, peer := range s.peers {
+		if peer.connect && !isIPOrOnionAddr(addr) {
+			resolvedAddrs[addr] = peer.resolvedName
+		}
+	}
+	s.peersMtx.RUnlock()
+
+	for addr, oldResolvedAddr := range resolvedAddrs {
+		if ctx.Err() != nil {
+			return
+		}
+		newResolvedAddr, err := s.resolveAddress(ctx, addr)
 		if err != nil {
-			s.log.Errorf("failed to add peer %s: %v", addr, err)
+			s.log.Debugf("failed to resolve peer %s: %v", addr, err)
+			continue
+		}
+		if newResolvedAddr != oldResolvedAddr {
+			s.updateResolvedAddr(addr, oldResolvedAddr, newResolvedAddr)
+		}
+	}
+}
+
+// updateResolvedAddr switches the connection to the peer at addr from its
+// old resolved address to its new resolved address.
+func (s *SPVPeerManager) updateResolvedAddr(addr, oldResolvedAddr, newResolvedAddr string) {
+	s.peersMtx.Lock()
+	defer s.peersMtx.Unlock()
+
+	peer, found := s.peers[addr]
+	if !found || peer.resolvedName != oldResolvedAddr {
+		return // peer was removed or updated while resolving
+	}
+	if duplicatePeer, found := s.peerWithResolvedAddr(newResolvedAddr); found {
+		s.log.Warnf("%s and %s now resolve to the same node", duplicatePeer, addr)
+		return
+	}
+
+	s.log.Infof("Peer %s resolved to new address %s", addr, newResolvedAddr)
+	peer.resolvedName = newResolvedAddr
+
+	connectedPeers := s.connectedPeers()
+	if _, connected := connectedPeers[oldResolvedAddr]; connected {
+		if err := s.cs.RemoveNodeByAddr(oldResolvedAddr); err != nil {
+			s.log.Errorf("failed to disconnect from %s at %s: %v", addr, oldResolvedAddr, err)
+		}
+	}
+	if s.isBanned(newResolvedAddr) {
+		return
+	}
+	if _, connected := connectedPeers[newResolvedAddr]; !connected {
+		if err := s.cs.ConnectNode(newResolvedAddr, true); err != nil {
+			s.log.Errorf("failed to connect to %s at %s: %v", addr, newResolvedAddr, err)
+		}
+	}
+}
+
+// ReresolvePeersPeriodically calls ReresolvePeers at the specified interval
+// until ctx is canceled.
+func (s *SPVPeerManager) ReresolvePeersPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ReresolvePeers(ctx)
 		}
 	}
 }
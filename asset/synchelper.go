@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/decred/slog"
 )
@@ -12,11 +13,118 @@ type syncHelper struct {
 	log slog.Logger
 
 	mtx        sync.Mutex
+	syncCtx    context.Context
 	cancelSync context.CancelFunc
 	// syncEndedCh is opened when sync is started and closed when sync is ended.
 	// Wait on this channel to know when sync has completely stopped.
-	syncEndedCh     chan struct{}
-	cancelRequested bool
+	syncEndedCh chan struct{}
+
+	state      SyncState
+	stateSince time.Time
+	lastErr    error
+	// stateBeforeRescan is the state to return to when a rescan ends. State
+	// changes reported during a rescan are saved here.
+	stateBeforeRescan SyncState
+	listeners         map[string]SyncStateListener
+	// pendingNtfns are the sync state notifications that are yet to be
+	// delivered, in the order of the state changes. dispatching is set while
+	// a goroutine is delivering them, so that they are delivered one at a
+	// time.
+	pendingNtfns []syncStateNtfn
+	dispatching  bool
+
+	// cancelRescan is set while a rescan is in progress.
+	cancelRescan context.CancelFunc
+}
+
+// syncStateNtfn is a sync status to deliver to the listeners that were
+// registered when the status was recorded.
+type syncStateNtfn struct {
+	status    SyncStatus
+	listeners []SyncStateListener
+}
+
+// setState changes the sync state and returns a function that notifies the
+// sync state listeners of the change. The returned function must be called
+// after sh.mtx is unlocked. sh.mtx MUST be held.
+func (sh *syncHelper) setState(state SyncState) (notify func()) {
+	if state != sh.state {
+		sh.log.Debugf("sync state changed from %s to %s", sh.state, state)
+		sh.state = state
+		sh.stateSince = time.Now()
+	}
+
+	listeners := make([]SyncStateListener, 0, len(sh.listeners))
+	for _, listener := range sh.listeners {
+		listeners = append(listeners, listener)
+	}
+	sh.pendingNtfns = append(sh.pendingNtfns, syncStateNtfn{sh.status(), listeners})
+	return sh.dispatchNtfns
+}
+
+// dispatchNtfns delivers the pending sync state notifications in the order of
+// the state changes. If another goroutine is already delivering them, it also
+// delivers the notifications queued by the caller, so listeners never see an
+// older state after a newer one. sh.mtx MUST NOT be held.
+func (sh *syncHelper) dispatchNtfns() {
+	sh.mtx.Lock()
+	if sh.dispatching {
+		sh.mtx.Unlock()
+		return
+	}
+	sh.dispatching = true
+	for len(sh.pendingNtfns) > 0 {
+		ntfn := sh.pendingNtfns[0]
+		sh.pendingNtfns = sh.pendingNtfns[1:]
+		sh.mtx.Unlock()
+		for _, listener := range ntfn.listeners {
+			listener(ntfn.status)
+		}
+		sh.mtx.Lock()
+	}
+	sh.dispatching = false
+	sh.mtx.Unlock()
+}
+
+// status returns a snapshot of the sync state. sh.mtx MUST be held.
+func (sh *syncHelper) status() SyncStatus {
+	return SyncStatus{
+		State:     sh.state,
+		Since:     sh.stateSince,
+		LastError: sh.lastErr,
+	}
+}
+
+// SyncStatus returns a snapshot of the wallet's sync state.
+func (sh *syncHelper) SyncStatus() SyncStatus {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+	return sh.status()
+}
+
+// AddSyncStateListener registers a listener that is notified of sync state
+// changes. uniqueID is used to remove the listener with
+// RemoveSyncStateListener.
+func (sh *syncHelper) AddSyncStateListener(uniqueID string, listener SyncStateListener) error {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+
+	if _, exists := sh.listeners[uniqueID]; exists {
+		return fmt.Errorf("sync state listener with id %q already exists", uniqueID)
+	}
+	if sh.listeners == nil {
+		sh.listeners = make(map[string]SyncStateListener)
+	}
+	sh.listeners[uniqueID] = listener
+	return nil
+}
+
+// RemoveSyncStateListener removes the sync state listener registered with
+// uniqueID, if any.
+func (sh *syncHelper) RemoveSyncStateListener(uniqueID string) {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+	delete(sh.listeners, uniqueID)
 }
 
 // InitializeSyncContext returns a context that should be used for bacgkround
@@ -24,16 +132,21 @@ type syncHelper struct {
 // context is canceled. Call SyncEnded() when all sync processes have ended.
 func (sh *syncHelper) InitializeSyncContext(ctx context.Context) (context.Context, error) {
 	sh.mtx.Lock()
-	defer sh.mtx.Unlock()
 
 	if sh.cancelSync != nil {
+		sh.mtx.Unlock()
 		return nil, fmt.Errorf("already syncing")
 	}
 
 	syncCtx, cancel := context.WithCancel(ctx)
+	sh.syncCtx = syncCtx
 	sh.cancelSync = cancel
 	sh.syncEndedCh = make(chan struct{})
-	sh.cancelRequested = false
+	sh.lastErr = nil
+	notify := sh.setState(SyncStateConnecting)
+	sh.mtx.Unlock()
+
+	notify()
 	return syncCtx, nil
 }
 
@@ -44,42 +157,105 @@ func (sh *syncHelper) IsSyncingOrSynced() bool {
 	return sh.cancelSync != nil
 }
 
-// SyncEnded signals that all sync processes have been stopped.
+// SetSyncState records the progress of an active sync. state must be one of
+// SyncStateConnecting, SyncStateSyncingHeaders, SyncStateSyncingFilters,
+// SyncStateRescanning or SyncStateSynced. The state is not changed if sync is
+// not running or is stopping.
+func (sh *syncHelper) SetSyncState(state SyncState) {
+	sh.mtx.Lock()
+
+	if !state.isActive() {
+		sh.mtx.Unlock()
+		sh.log.Errorf("invalid state for active sync: %s", state)
+		return
+	}
+	if sh.cancelSync == nil || !sh.state.isActive() {
+		sh.mtx.Unlock()
+		return
+	}
+	if sh.cancelRescan != nil && state != SyncStateRescanning {
+		// Restore this state when the rescan ends.
+		sh.stateBeforeRescan = state
+		sh.mtx.Unlock()
+		return
+	}
+	if state == sh.state {
+		sh.mtx.Unlock()
+		return
+	}
+
+	notify := sh.setState(state)
+	sh.mtx.Unlock()
+	notify()
+}
+
+// SetSyncError records an error that was encountered by an active sync that
+// did not cause the sync to end, such as a failed connection attempt.
+func (sh *syncHelper) SetSyncError(err error) {
+	sh.mtx.Lock()
+
+	if sh.cancelSync == nil {
+		sh.mtx.Unlock()
+		return
+	}
+
+	sh.lastErr = err
+	notify := sh.setState(sh.state)
+	sh.mtx.Unlock()
+	notify()
+}
+
+// SyncEnded signals that all sync processes have been stopped. A non-nil err
+// indicates that sync ended because of the error.
 func (sh *syncHelper) SyncEnded(err error) {
 	sh.mtx.Lock()
-	defer sh.mtx.Unlock()
 
 	if sh.cancelSync == nil {
+		sh.mtx.Unlock()
 		return // sync wasn't active
 	}
 
 	close(sh.syncEndedCh)
+	sh.syncCtx = nil
 	sh.cancelSync = nil
 	sh.syncEndedCh = nil
-	sh.cancelRequested = false
-	sh.log.Infof("sync canceled")
+
+	var notify func()
+	if err != nil {
+		sh.log.Errorf("sync ended with error: %v", err)
+		sh.lastErr = err
+		notify = sh.setState(SyncStateFailed)
+	} else {
+		sh.log.Infof("sync stopped")
+		notify = sh.setState(SyncStateIdle)
+	}
+	sh.mtx.Unlock()
+	notify()
 }
 
 // StopSync cancels the wallet's synchronization to the blockchain network. It
-// may take a few moments for sync to completely stop. Use
+// may take a few moments for sync to completely stop. Use WaitForSyncToStop to
+// wait for sync to stop.
 func (sh *syncHelper) StopSync() {
 	sh.mtx.Lock()
-	defer sh.mtx.Unlock()
 
-	if sh.cancelRequested || sh.cancelSync == nil {
+	if sh.state == SyncStateStopping || sh.cancelSync == nil {
+		sh.mtx.Unlock()
 		sh.log.Infof("sync is already canceling or not running")
 		return
 	}
 
 	sh.log.Infof("canceling sync... this may take a moment")
-	sh.cancelRequested = true
+	notify := sh.setState(SyncStateStopping)
 	sh.cancelSync()
+	sh.mtx.Unlock()
+	notify()
 }
 
 func (sh *syncHelper) SyncIsStopping() bool {
 	sh.mtx.Lock()
 	defer sh.mtx.Unlock()
-	return sh.cancelRequested
+	return sh.state == SyncStateStopping
 }
 
 // WaitForSyncToStop blocks until the wallet synchronization is fully stopped.
@@ -92,3 +268,78 @@ func (sh *syncHelper) WaitForSyncToStop() {
 		<-waitCh
 	}
 }
+
+// InitializeRescanContext returns a context that should be used for a wallet
+// rescan. The returned context is canceled when the provided ctx is canceled,
+// when CancelRescan is called or when sync is stopped. Sync must be active
+// before a rescan can be started. Call RescanEnded() when the rescan has ended.
+func (sh *syncHelper) InitializeRescanContext(ctx context.Context) (context.Context, error) {
+	sh.mtx.Lock()
+
+	if sh.cancelSync == nil || sh.state == SyncStateStopping {
+		sh.mtx.Unlock()
+		return nil, ErrNotSyncing
+	}
+	if sh.cancelRescan != nil {
+		sh.mtx.Unlock()
+		return nil, fmt.Errorf("already rescanning")
+	}
+
+	rescanCtx, cancel := context.WithCancel(ctx)
+	sh.cancelRescan = cancel
+	sh.stateBeforeRescan = sh.state
+	notify := sh.setState(SyncStateRescanning)
+
+	// Also cancel the rescan if sync is stopped.
+	syncCtx := sh.syncCtx
+	go func() {
+		select {
+		case <-syncCtx.Done():
+			cancel()
+		case <-rescanCtx.Done():
+		}
+	}()
+
+	sh.mtx.Unlock()
+	notify()
+	return rescanCtx, nil
+}
+
+// IsRescanning is true if a wallet rescan is in progress.
+func (sh *syncHelper) IsRescanning() bool {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+	return sh.cancelRescan != nil
+}
+
+// RescanEnded signals that the wallet rescan has ended.
+func (sh *syncHelper) RescanEnded() {
+	sh.mtx.Lock()
+
+	if sh.cancelRescan == nil {
+		sh.mtx.Unlock()
+		return
+	}
+
+	sh.cancelRescan()
+	sh.cancelRescan = nil
+	if sh.state != SyncStateRescanning {
+		// Sync was stopped or ended during the rescan.
+		sh.mtx.Unlock()
+		return
+	}
+	notify := sh.setState(sh.stateBeforeRescan)
+	sh.mtx.Unlock()
+	notify()
+}
+
+// CancelRescan cancels an ongoing wallet rescan, if any.
+func (sh *syncHelper) CancelRescan() {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+
+	if sh.cancelRescan != nil {
+		sh.log.Infof("canceling rescan")
+		sh.cancelRescan()
+	}
+}
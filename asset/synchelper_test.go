@@ -0,0 +1,69 @@
+package asset
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/decred/slog"
+)
+
+// TestSyncStateNotificationOrder checks that listeners receive the sync state
+// changes in the order they were made when the state is changed concurrently.
+func TestSyncStateNotificationOrder(t *testing.T) {
+	const (
+		changers = 8
+		changes  = 200
+	)
+
+	sh := &syncHelper{log: slog.Disabled}
+
+	var mtx sync.Mutex
+	var received []SyncStatus
+	err := sh.AddSyncStateListener("test", func(status SyncStatus) {
+		// Yield to let other goroutines change the state while listeners
+		// are being notified.
+		runtime.Gosched()
+		mtx.Lock()
+		received = append(received, status)
+		mtx.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("error adding sync state listener: %v", err)
+	}
+
+	if _, err := sh.InitializeSyncContext(context.Background()); err != nil {
+		t.Fatalf("error initializing sync context: %v", err)
+	}
+
+	states := []SyncState{SyncStateConnecting, SyncStateSyncingHeaders, SyncStateSyncingFilters, SyncStateSynced}
+	var wg sync.WaitGroup
+	for i := 0; i < changers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < changes; j++ {
+				sh.SetSyncState(states[(i+j)%len(states)])
+			}
+		}(i)
+	}
+	wg.Wait()
+	sh.StopSync()
+	sh.SyncEnded(nil)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for i := 1; i < len(received); i++ {
+		if received[i].Since.Before(received[i-1].Since) {
+			t.Fatalf("notification %d for state %s changed at %v was received after state %s changed at %v",
+				i, received[i].State, received[i].Since, received[i-1].State, received[i-1].Since)
+		}
+		if received[i].State == received[i-1].State {
+			t.Fatalf("notification %d repeats state %s", i, received[i].State)
+		}
+	}
+	if last := received[len(received)-1]; last != sh.SyncStatus() {
+		t.Fatalf("last notification is for state %s, but the sync state is %s", last.State, sh.SyncStatus().State)
+	}
+}
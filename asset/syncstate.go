@@ -0,0 +1,79 @@
+package asset
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyncState is the state of a wallet's synchronization to the blockchain
+// network.
+type SyncState uint8
+
+const (
+	// SyncStateIdle means that sync is not running.
+	SyncStateIdle SyncState = iota
+	// SyncStateConnecting means that sync was started and the wallet is
+	// waiting to connect to peers.
+	SyncStateConnecting
+	// SyncStateSyncingHeaders means that the wallet is fetching block headers.
+	SyncStateSyncingHeaders
+	// SyncStateSyncingFilters means that the wallet is fetching and checking
+	// compact block filters.
+	SyncStateSyncingFilters
+	// SyncStateRescanning means that the wallet is rescanning the blockchain
+	// for relevant transactions.
+	SyncStateRescanning
+	// SyncStateSynced means that the wallet is synced to the best block on
+	// the main chain.
+	SyncStateSynced
+	// SyncStateStopping means that sync was canceled and is stopping.
+	SyncStateStopping
+	// SyncStateFailed means that sync ended because of an error. The error
+	// is available as SyncStatus.LastError.
+	SyncStateFailed
+)
+
+// String returns a human-readable representation of the sync state.
+func (s SyncState) String() string {
+	switch s {
+	case SyncStateIdle:
+		return "idle"
+	case SyncStateConnecting:
+		return "connecting"
+	case SyncStateSyncingHeaders:
+		return "syncing headers"
+	case SyncStateSyncingFilters:
+		return "syncing filters"
+	case SyncStateRescanning:
+		return "rescanning"
+	case SyncStateSynced:
+		return "synced"
+	case SyncStateStopping:
+		return "stopping"
+	case SyncStateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown sync state %d", uint8(s))
+	}
+}
+
+// isActive returns true if the state is one that sync can be in while it is
+// running and not being stopped.
+func (s SyncState) isActive() bool {
+	return s >= SyncStateConnecting && s <= SyncStateSynced
+}
+
+// SyncStatus is a snapshot of a wallet's sync state.
+type SyncStatus struct {
+	State SyncState
+	// Since is the time that the wallet entered State.
+	Since time.Time
+	// LastError is the last error encountered while syncing, if any. It is
+	// cleared when sync is started.
+	LastError error
+}
+
+// SyncStateListener is notified when a wallet's sync state changes. Listeners
+// are called one notification at a time, in the order of the state changes,
+// and may be called from any goroutine.
+type SyncStateListener func(status SyncStatus)
@@ -0,0 +1,254 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/decred/slog"
+)
+
+// SyncHealth describes the health of a running sync as determined by a
+// SyncSupervisor.
+type SyncHealth uint8
+
+const (
+	// SyncHealthy means that the wallet is receiving new headers or is synced
+	// and connected to peers.
+	SyncHealthy SyncHealth = iota
+	// SyncStalled means that no new headers were received for the configured
+	// stall timeout while the wallet is not synced.
+	SyncStalled
+	// SyncNoPeers means that the wallet had no peers for the configured stall
+	// timeout.
+	SyncNoPeers
+	// SyncRestarting means that the supervisor is restarting the sync.
+	SyncRestarting
+)
+
+// String returns a human-readable representation of the sync health.
+func (h SyncHealth) String() string {
+	switch h {
+	case SyncHealthy:
+		return "healthy"
+	case SyncStalled:
+		return "stalled"
+	case SyncNoPeers:
+		return "no peers"
+	case SyncRestarting:
+		return "restarting"
+	default:
+		return fmt.Sprintf("unknown sync health %d", uint8(h))
+	}
+}
+
+// SyncHealthListener is called when the sync health changes. err is set if the
+// supervisor failed to restart the sync.
+type SyncHealthListener func(health SyncHealth, err error)
+
+const (
+	defaultSyncStallTimeout  = 10 * time.Minute
+	defaultSyncCheckInterval = 30 * time.Second
+	defaultSyncMinBackoff    = 10 * time.Second
+	defaultSyncMaxBackoff    = 5 * time.Minute
+)
+
+// SyncSupervisorConfig configures a SyncSupervisor. Zero values are replaced
+// with defaults.
+type SyncSupervisorConfig struct {
+	// StallTimeout is how long the wallet may go without new headers while
+	// not synced, or without peers, before the sync is restarted. Defaults to
+	// 10 minutes.
+	StallTimeout time.Duration
+	// CheckInterval is how often the sync health is checked. Defaults to 30
+	// seconds.
+	CheckInterval time.Duration
+	// MinBackoff and MaxBackoff bound the delay between consecutive restarts
+	// that do not result in progress. The delay doubles after each such
+	// restart. Default to 10 seconds and 5 minutes respectively.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Listener, if set, is notified of sync health changes.
+	Listener SyncHealthListener
+}
+
+// SyncSupervisorBackend are the functions needed for a SyncSupervisor to
+// monitor and restart a wallet's sync.
+type SyncSupervisorBackend interface {
+	// BestHeaderHeight returns the height of the best header known to the
+	// wallet's chain service.
+	BestHeaderHeight() (int32, error)
+	// SyncedHeight returns the height of the last block processed by the
+	// wallet, which advances while the wallet catches up to the best header
+	// and during rescans.
+	SyncedHeight() int32
+	// PeerCount returns the number of peers that the wallet is connected to.
+	PeerCount() int
+	// IsSynced returns true if the wallet is synced.
+	IsSynced() bool
+	// IsRescanning returns true if a wallet rescan is in progress. A rescan
+	// may take longer than the stall timeout without new headers.
+	IsRescanning() bool
+	// RestartSync restarts the wallet's sync.
+	RestartSync(ctx context.Context) error
+}
+
+// SyncSupervisor monitors a wallet's sync and restarts it with backoff if it
+// stalls or the wallet loses all of its peers.
+type SyncSupervisor struct {
+	backend SyncSupervisorBackend
+	cfg     SyncSupervisorConfig
+	log     slog.Logger
+
+	health           SyncHealth
+	lastHeight       int32
+	lastSyncedHeight int32
+	lastProgress     time.Time
+	lastPeerTime     time.Time
+	backoff          time.Duration
+}
+
+// NewSyncSupervisor creates a SyncSupervisor for backend. cfg may be nil to use
+// the default configuration.
+func NewSyncSupervisor(backend SyncSupervisorBackend, cfg *SyncSupervisorConfig, log slog.Logger) *SyncSupervisor {
+	s := &SyncSupervisor{
+		backend: backend,
+		log:     log,
+	}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	if s.cfg.StallTimeout <= 0 {
+		s.cfg.StallTimeout = defaultSyncStallTimeout
+	}
+	if s.cfg.CheckInterval <= 0 {
+		s.cfg.CheckInterval = defaultSyncCheckInterval
+	}
+	if s.cfg.MinBackoff <= 0 {
+		s.cfg.MinBackoff = defaultSyncMinBackoff
+	}
+	if s.cfg.MaxBackoff < s.cfg.MinBackoff {
+		s.cfg.MaxBackoff = defaultSyncMaxBackoff
+		if s.cfg.MaxBackoff < s.cfg.MinBackoff {
+			s.cfg.MaxBackoff = s.cfg.MinBackoff
+		}
+	}
+	return s
+}
+
+// Run monitors the sync until ctx is canceled. Restarts are performed from
+// this goroutine, so callers can stop the sync safely after Run returns.
+func (s *SyncSupervisor) Run(ctx context.Context) {
+	now := time.Now()
+	s.lastProgress, s.lastPeerTime = now, now
+	s.lastHeight, _ = s.backend.BestHeaderHeight()
+	s.lastSyncedHeight = s.backend.SyncedHeight()
+	s.backoff = s.cfg.MinBackoff
+
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		health := s.checkHealth(time.Now())
+		s.setHealth(health, nil)
+		if health == SyncHealthy {
+			continue
+		}
+
+		s.restart(ctx)
+	}
+}
+
+// checkHealth determines the sync health at the time now. New headers, blocks
+// processed by the wallet and running rescans count as progress.
+func (s *SyncSupervisor) checkHealth(now time.Time) SyncHealth {
+	progressed := false
+	height, err := s.backend.BestHeaderHeight()
+	if err != nil {
+		s.log.Errorf("Sync supervisor failed to read best header height: %v", err)
+	} else if height > s.lastHeight {
+		s.lastHeight = height
+		progressed = true
+	}
+	// The synced height moves back when a rescan starts, so any change is
+	// progress.
+	if syncedHeight := s.backend.SyncedHeight(); syncedHeight != s.lastSyncedHeight {
+		s.lastSyncedHeight = syncedHeight
+		progressed = true
+	}
+	if s.backend.IsRescanning() {
+		progressed = true
+	}
+	if progressed {
+		s.lastProgress = now
+		// Progress resets the restart backoff.
+		s.backoff = s.cfg.MinBackoff
+	}
+
+	if s.backend.PeerCount() > 0 {
+		s.lastPeerTime = now
+	} else if now.Sub(s.lastPeerTime) >= s.cfg.StallTimeout {
+		return SyncNoPeers
+	}
+
+	if !s.backend.IsSynced() && now.Sub(s.lastProgress) >= s.cfg.StallTimeout {
+		return SyncStalled
+	}
+
+	return SyncHealthy
+}
+
+// restart restarts the sync, retrying with backoff until the restart succeeds
+// or ctx is canceled.
+func (s *SyncSupervisor) restart(ctx context.Context) {
+	s.log.Infof("Sync health is %s, restarting sync", s.health)
+	s.setHealth(SyncRestarting, nil)
+	for {
+		err := s.backend.RestartSync(ctx)
+		if err != nil {
+			s.log.Errorf("Failed to restart sync: %v", err)
+			s.setHealth(SyncRestarting, err)
+		}
+
+		// Wait before the next check or restart attempt, so that repeated
+		// restarts without progress back off.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.backoff):
+		}
+		s.backoff *= 2
+		if s.backoff > s.cfg.MaxBackoff {
+			s.backoff = s.cfg.MaxBackoff
+		}
+
+		if err == nil {
+			// Give the restarted sync a full stall timeout to make progress.
+			now := time.Now()
+			s.lastProgress, s.lastPeerTime = now, now
+			s.setHealth(SyncHealthy, nil)
+			return
+		}
+	}
+}
+
+// setHealth updates the sync health and notifies the listener if the health
+// changed or err is set.
+func (s *SyncSupervisor) setHealth(health SyncHealth, err error) {
+	if health == s.health && err == nil {
+		return
+	}
+	if health != s.health {
+		s.log.Debugf("Sync health changed from %s to %s", s.health, health)
+	}
+	s.health = health
+	if s.cfg.Listener != nil {
+		s.cfg.Listener(health, err)
+	}
+}
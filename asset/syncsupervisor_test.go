@@ -0,0 +1,251 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/slog"
+)
+
+// fakeSyncBackend is a SyncSupervisorBackend whose state is set by the tests.
+type fakeSyncBackend struct {
+	mtx          sync.Mutex
+	headerHeight int32
+	syncedHeight int32
+	peers        int
+	synced       bool
+	rescanning   bool
+	// restartErrs are returned by the next calls to RestartSync, which
+	// succeeds once they are used up.
+	restartErrs []error
+	restarts    []time.Time
+}
+
+func (b *fakeSyncBackend) BestHeaderHeight() (int32, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.headerHeight, nil
+}
+
+func (b *fakeSyncBackend) SyncedHeight() int32 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.syncedHeight
+}
+
+func (b *fakeSyncBackend) PeerCount() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.peers
+}
+
+func (b *fakeSyncBackend) IsSynced() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.synced
+}
+
+func (b *fakeSyncBackend) IsRescanning() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.rescanning
+}
+
+func (b *fakeSyncBackend) RestartSync(context.Context) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.restarts = append(b.restarts, time.Now())
+	if len(b.restartErrs) == 0 {
+		return nil
+	}
+	err := b.restartErrs[0]
+	b.restartErrs = b.restartErrs[1:]
+	return err
+}
+
+func (b *fakeSyncBackend) restartTimes() []time.Time {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return append([]time.Time(nil), b.restarts...)
+}
+
+// TestSyncSupervisorCheckHealth checks the sync health determined from the
+// backend's state after the stall timeout elapsed since the last check.
+func TestSyncSupervisorCheckHealth(t *testing.T) {
+	const stallTimeout = time.Minute
+
+	tests := []struct {
+		name    string
+		backend *fakeSyncBackend
+		elapsed time.Duration
+		health  SyncHealth
+	}{{
+		name:    "new headers",
+		backend: &fakeSyncBackend{headerHeight: 11, syncedHeight: 5, peers: 1},
+		elapsed: stallTimeout,
+		health:  SyncHealthy,
+	}, {
+		name:    "new blocks processed",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 6, peers: 1},
+		elapsed: stallTimeout,
+		health:  SyncHealthy,
+	}, {
+		name:    "rescan started",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 0, peers: 1},
+		elapsed: stallTimeout,
+		health:  SyncHealthy,
+	}, {
+		name:    "rescanning",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 5, peers: 1, rescanning: true},
+		elapsed: stallTimeout,
+		health:  SyncHealthy,
+	}, {
+		name:    "synced without new blocks",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 5, peers: 1, synced: true},
+		elapsed: stallTimeout,
+		health:  SyncHealthy,
+	}, {
+		name:    "no progress before the stall timeout",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 5, peers: 1},
+		elapsed: stallTimeout - time.Second,
+		health:  SyncHealthy,
+	}, {
+		name:    "stalled",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 5, peers: 1},
+		elapsed: stallTimeout,
+		health:  SyncStalled,
+	}, {
+		name:    "no peers before the stall timeout",
+		backend: &fakeSyncBackend{headerHeight: 10, syncedHeight: 5, synced: true},
+		elapsed: stallTimeout - time.Second,
+		health:  SyncHealthy,
+	}, {
+		name:    "no peers",
+		backend: &fakeSyncBackend{headerHeight: 11, syncedHeight: 6, synced: true},
+		elapsed: stallTimeout,
+		health:  SyncNoPeers,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewSyncSupervisor(test.backend, &SyncSupervisorConfig{StallTimeout: stallTimeout}, slog.Disabled)
+			start := time.Now()
+			s.lastProgress, s.lastPeerTime = start, start
+			s.lastHeight, s.lastSyncedHeight = 10, 5
+
+			if health := s.checkHealth(start.Add(test.elapsed)); health != test.health {
+				t.Fatalf("expected sync health %s, got %s", test.health, health)
+			}
+		})
+	}
+}
+
+// TestSyncSupervisorRestartsStalledSync checks that a stalled sync is restarted,
+// that failed restarts are retried with a backoff that doubles up to the
+// maximum backoff, and that the listener is notified of every health change.
+func TestSyncSupervisorRestartsStalledSync(t *testing.T) {
+	const (
+		minBackoff = 20 * time.Millisecond
+		maxBackoff = 40 * time.Millisecond
+	)
+
+	restartErr := errors.New("restart failed")
+	backend := &fakeSyncBackend{
+		peers:       1,
+		restartErrs: []error{restartErr, restartErr, restartErr},
+	}
+
+	type healthChange struct {
+		health SyncHealth
+		err    error
+	}
+	changes := make(chan healthChange, 100)
+	cfg := &SyncSupervisorConfig{
+		StallTimeout:  10 * time.Millisecond,
+		CheckInterval: 5 * time.Millisecond,
+		MinBackoff:    minBackoff,
+		MaxBackoff:    maxBackoff,
+		Listener: func(health SyncHealth, err error) {
+			select {
+			case changes <- healthChange{health, err}:
+			default:
+			}
+		},
+	}
+
+	s := NewSyncSupervisor(backend, cfg, slog.Disabled)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	expectedChanges := []healthChange{
+		{SyncStalled, nil},
+		{SyncRestarting, nil},
+		{SyncRestarting, restartErr},
+		{SyncRestarting, restartErr},
+		{SyncRestarting, restartErr},
+		{SyncHealthy, nil},
+	}
+	for _, expected := range expectedChanges {
+		select {
+		case change := <-changes:
+			if change != expected {
+				t.Fatalf("expected health change %v, got %v", expected, change)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for health change %v", expected)
+		}
+	}
+
+	cancel()
+	<-done
+
+	restarts := backend.restartTimes()
+	if len(restarts) < 4 {
+		t.Fatalf("expected at least 4 restarts, got %d", len(restarts))
+	}
+	for i, minDelay := range []time.Duration{minBackoff, 2 * minBackoff, maxBackoff} {
+		if delay := restarts[i+1].Sub(restarts[i]); delay < minDelay {
+			t.Fatalf("expected restart %d to be delayed by at least %s, got %s", i+1, minDelay, delay)
+		}
+	}
+	if s.backoff != maxBackoff {
+		t.Fatalf("expected backoff to be capped at %s, got %s", maxBackoff, s.backoff)
+	}
+}
+
+// TestSyncSupervisorDoesNotRestartHealthySync checks that a sync that keeps
+// receiving headers is not restarted.
+func TestSyncSupervisorDoesNotRestartHealthySync(t *testing.T) {
+	backend := &fakeSyncBackend{peers: 1}
+	cfg := &SyncSupervisorConfig{
+		StallTimeout:  200 * time.Millisecond,
+		CheckInterval: 5 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewSyncSupervisor(backend, cfg, slog.Disabled).Run(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 100; i++ {
+		backend.mtx.Lock()
+		backend.headerHeight++
+		backend.mtx.Unlock()
+		time.Sleep(cfg.CheckInterval)
+	}
+	cancel()
+	<-done
+
+	if restarts := backend.restartTimes(); len(restarts) != 0 {
+		t.Fatalf("expected no restarts, got %d", len(restarts))
+	}
+}
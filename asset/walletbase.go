@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"time"
 
 	"decred.org/dcrwallet/v3/walletseed"
 	"github.com/decred/slog"
@@ -14,8 +15,14 @@ const (
 	walletTraitsDBKey             = "traits"
 	encryptedSeedDBKey            = "encryptedSeed"
 	accountDiscoveryRequiredDBKey = "accountDiscoveryRequired"
+	birthdayDBKey                 = "birthday"
+	birthdayBlockDBKey            = "birthdayBlock"
 )
 
+// unknownBirthdayBlock is used in place of the birthday block height if the
+// wallet's birthday is yet to be mapped to a block.
+const unknownBirthdayBlock = -1
+
 type WalletBase[Tx any] struct {
 	// UserConfigDB is publicly embedded, so consumers can directly read from or
 	// write to the user config db.
@@ -30,18 +37,30 @@ type WalletBase[Tx any] struct {
 	log     slog.Logger
 	dataDir string
 	network Network
+	// storePeersInDB is true if SPV peers should be persisted to db rather
+	// than to files in dataDir.
+	storePeersInDB bool
+	peerResolver   Resolver
+	preferIPv6     bool
+
+	syncSupervisorCfg *SyncSupervisorConfig
+	watchList         *WatchList
 
 	mtx                      sync.Mutex
 	traits                   WalletTrait
 	encryptedSeed            []byte
 	accountDiscoveryRequired bool
+	birthday                 time.Time
+	birthdayBlock            int32
 
 	*syncHelper
+	*eventHub
 }
 
 // NewWalletBase initializes a WalletBase using the information provided. The
-// wallet's seed is encrypted and saved, along with other basic wallet info.
-func NewWalletBase[Tx any](params OpenWalletParams[Tx], seed, walletPass []byte, traits WalletTrait) (*WalletBase[Tx], error) {
+// wallet's seed is encrypted and saved, along with the wallet's birthday and
+// other basic wallet info.
+func NewWalletBase[Tx any](params OpenWalletParams[Tx], seed, walletPass []byte, birthday time.Time, traits WalletTrait) (*WalletBase[Tx], error) {
 	isWatchOnly, isRestored := isWatchOnly(traits), isRestored(traits)
 	if isWatchOnly && isRestored {
 		return nil, fmt.Errorf("invalid wallet traits: restored wallet cannot be watch only")
@@ -72,6 +91,7 @@ func NewWalletBase[Tx any](params OpenWalletParams[Tx], seed, walletPass []byte,
 	dbData := map[string]any{
 		walletTraitsDBKey:             traits,
 		accountDiscoveryRequiredDBKey: accountDiscoveryRequired,
+		birthdayDBKey:                 birthday,
 	}
 	if len(encryptedSeed) > 0 {
 		dbData[encryptedSeedDBKey] = encryptedSeed
@@ -89,10 +109,18 @@ func NewWalletBase[Tx any](params OpenWalletParams[Tx], seed, walletPass []byte,
 		log:                      params.Logger,
 		dataDir:                  params.DataDir,
 		network:                  params.Net,
+		storePeersInDB:           params.StorePeersInWalletDB,
+		peerResolver:             params.PeerResolver,
+		preferIPv6:               params.PreferIPv6,
+		syncSupervisorCfg:        params.SyncSupervisor,
+		watchList:                NewWatchList(),
 		traits:                   traits,
 		encryptedSeed:            encryptedSeed,
 		accountDiscoveryRequired: accountDiscoveryRequired,
+		birthday:                 birthday,
+		birthdayBlock:            unknownBirthdayBlock,
 		syncHelper:               &syncHelper{log: params.Logger},
+		eventHub:                 newEventHub(params.Logger),
 	}, nil
 }
 
@@ -100,13 +128,20 @@ func NewWalletBase[Tx any](params OpenWalletParams[Tx], seed, walletPass []byte,
 // provided params.
 func OpenWalletBase[Tx any](params OpenWalletParams[Tx]) (*WalletBase[Tx], error) {
 	w := &WalletBase[Tx]{
-		UserConfigDB: params.UserConfigDB,
-		TxIndexDB:    params.TxIndexDB,
-		db:           params.WalletConfigDB,
-		log:          params.Logger,
-		dataDir:      params.DataDir,
-		network:      params.Net,
-		syncHelper:   &syncHelper{log: params.Logger},
+		UserConfigDB:      params.UserConfigDB,
+		TxIndexDB:         params.TxIndexDB,
+		db:                params.WalletConfigDB,
+		log:               params.Logger,
+		dataDir:           params.DataDir,
+		network:           params.Net,
+		storePeersInDB:    params.StorePeersInWalletDB,
+		peerResolver:      params.PeerResolver,
+		preferIPv6:        params.PreferIPv6,
+		syncSupervisorCfg: params.SyncSupervisor,
+		watchList:         NewWatchList(),
+		birthdayBlock:     unknownBirthdayBlock,
+		syncHelper:        &syncHelper{log: params.Logger},
+		eventHub:          newEventHub(params.Logger),
 	}
 
 	readFromDB := func(key string, wFieldPtr any) error {
@@ -125,6 +160,16 @@ func OpenWalletBase[Tx any](params OpenWalletParams[Tx]) (*WalletBase[Tx], error
 		return nil, err
 	}
 
+	// The birthday and birthday block may not be saved for wallets created
+	// before birthdays were recorded or for wallets whose birthday is yet to
+	// be mapped to a block, so failing to read them is not an error.
+	if err := readFromDB(birthdayDBKey, &w.birthday); err != nil {
+		w.log.Debugf("wallet birthday not found: %v", err)
+	}
+	if err := readFromDB(birthdayBlockDBKey, &w.birthdayBlock); err != nil {
+		w.birthdayBlock = unknownBirthdayBlock
+	}
+
 	return w, nil
 }
 
@@ -136,6 +181,36 @@ func (w *WalletBase[_]) Network() Network {
 	return w.network
 }
 
+// PeerStore returns the store used to persist the wallet's SPV peers. Peers
+// are stored in the wallet config db if the wallet was opened with
+// StorePeersInWalletDB set, otherwise in savedPeersFilePath.
+func (w *WalletBase[_]) PeerStore(savedPeersFilePath string) PeerStore {
+	if w.storePeersInDB {
+		return NewWalletDBPeerStore(w.db)
+	}
+	return NewFilePeerStore(savedPeersFilePath, w.log)
+}
+
+// NewSPVPeerManager creates an SPVPeerManager for the wallet, using the peer
+// store and peer resolution options that the wallet was opened with.
+func (w *WalletBase[_]) NewSPVPeerManager(cs PeerManagerChainService, defaultPeers []string, savedPeersFilePath, defaultPort string, proxy *ProxyConfig) *SPVPeerManager {
+	peerManager := NewSPVPeerManager(cs, defaultPeers, w.PeerStore(savedPeersFilePath), w.log, defaultPort, proxy)
+	peerManager.SetResolver(w.peerResolver, DefaultResolveTimeout, w.preferIPv6)
+	return peerManager
+}
+
+// WatchList returns the scripts and outpoints watched by the wallet in addition
+// to its own addresses.
+func (w *WalletBase[_]) WatchList() *WatchList {
+	return w.watchList
+}
+
+// NewSyncSupervisor creates a SyncSupervisor for the wallet's sync, using the
+// supervisor config that the wallet was opened with.
+func (w *WalletBase[_]) NewSyncSupervisor(backend SyncSupervisorBackend) *SyncSupervisor {
+	return NewSyncSupervisor(backend, w.syncSupervisorCfg, w.log)
+}
+
 // DecryptSeed decrypts the encrypted wallet seed using the provided passphrase.
 func (w *WalletBase[_]) DecryptSeed(passphrase []byte) (string, error) {
 	w.mtx.Lock()
@@ -245,6 +320,61 @@ func (w *WalletBase[_]) MarkAccountDiscoveryComplete() {
 	}
 }
 
+// Birthday returns the wallet's birthday, the earliest time that the wallet
+// may have been used. A zero time is returned if the birthday is not known.
+func (w *WalletBase[_]) Birthday() time.Time {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.birthday
+}
+
+// BirthdayBlock returns the height of the last block mined before the wallet's
+// birthday. Returns false if the birthday is yet to be mapped to a block.
+func (w *WalletBase[_]) BirthdayBlock() (int32, bool) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.birthdayBlock, w.birthdayBlock != unknownBirthdayBlock
+}
+
+// SaveBirthday saves the wallet's birthday and clears the previously saved
+// birthday block. This does not update the birthday used by the wallet
+// backend, asset wallets should expose a SetBirthday method for that purpose.
+func (w *WalletBase[_]) SaveBirthday(birthday time.Time) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if err := w.db.SaveWalletConfigValue(birthdayDBKey, birthday); err != nil {
+		w.log.Errorf("db.SaveWalletConfigValue(birthday) error: %v", err)
+		return fmt.Errorf("database error")
+	}
+	w.birthday = birthday
+
+	if w.birthdayBlock != unknownBirthdayBlock {
+		if err := w.db.DeleteWalletConfigValue(birthdayBlockDBKey); err != nil {
+			w.log.Errorf("db.DeleteWalletConfigValue(birthdayBlock) error: %v", err)
+			return fmt.Errorf("database error")
+		}
+		w.birthdayBlock = unknownBirthdayBlock
+	}
+
+	return nil
+}
+
+// SaveBirthdayBlock saves the height of the block that the wallet's birthday
+// maps to.
+func (w *WalletBase[_]) SaveBirthdayBlock(height int32) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if err := w.db.SaveWalletConfigValue(birthdayBlockDBKey, height); err != nil {
+		w.log.Errorf("db.SaveWalletConfigValue(birthdayBlock) error: %v", err)
+		return fmt.Errorf("database error")
+	}
+
+	w.birthdayBlock = height
+	return nil
+}
+
 // ReadUserConfigBoolValue is a helper method for reading a bool user config
 // value from the wallet's config db.
 func (w *WalletBase[_]) ReadUserConfigBoolValue(key string, defaultValue ...bool) bool {
@@ -0,0 +1,153 @@
+package asset
+
+import "sync"
+
+// WatchedOutpoint is an outpoint that is watched for spends.
+type WatchedOutpoint struct {
+	// PkScript is the script of the output, used to match the spending
+	// transaction against compact block filters.
+	PkScript    []byte
+	StartHeight int32
+}
+
+// WatchList holds the scripts and outpoints that a wallet watches in addition
+// to its own addresses. Each is watched from a start height. Watches are not
+// persisted and must be registered again after the wallet is loaded.
+type WatchList struct {
+	mtx sync.RWMutex
+	// scripts maps watched scripts to the height from which they are watched.
+	scripts map[string]int32
+	// outpoints maps the string representations of watched outpoints to the
+	// outpoints' details.
+	outpoints map[string]*WatchedOutpoint
+}
+
+// NewWatchList returns an empty WatchList.
+func NewWatchList() *WatchList {
+	return &WatchList{
+		scripts:   make(map[string]int32),
+		outpoints: make(map[string]*WatchedOutpoint),
+	}
+}
+
+// AddScript watches pkScript from startHeight. If pkScript is already
+// watched, the lower of the start heights is kept.
+func (wl *WatchList) AddScript(pkScript []byte, startHeight int32) {
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+
+	if height, found := wl.scripts[string(pkScript)]; found && height <= startHeight {
+		return
+	}
+	wl.scripts[string(pkScript)] = startHeight
+}
+
+// RemoveScript stops watching pkScript.
+func (wl *WatchList) RemoveScript(pkScript []byte) {
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+	delete(wl.scripts, string(pkScript))
+}
+
+// AddOutpoint watches the outpoint whose string representation is outpoint for
+// spends from startHeight. If the outpoint is already watched, the lower of
+// the start heights is kept.
+func (wl *WatchList) AddOutpoint(outpoint string, pkScript []byte, startHeight int32) {
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+
+	if op, found := wl.outpoints[outpoint]; found && op.StartHeight <= startHeight {
+		return
+	}
+	wl.outpoints[outpoint] = &WatchedOutpoint{
+		PkScript:    pkScript,
+		StartHeight: startHeight,
+	}
+}
+
+// RemoveOutpoint stops watching the outpoint whose string representation is
+// outpoint.
+func (wl *WatchList) RemoveOutpoint(outpoint string) {
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+	delete(wl.outpoints, outpoint)
+}
+
+// IsWatchedScript returns true if pkScript is watched.
+func (wl *WatchList) IsWatchedScript(pkScript []byte) bool {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	_, found := wl.scripts[string(pkScript)]
+	return found
+}
+
+// IsWatchedOutpoint returns true if the outpoint whose string representation is
+// outpoint is watched.
+func (wl *WatchList) IsWatchedOutpoint(outpoint string) bool {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	_, found := wl.outpoints[outpoint]
+	return found
+}
+
+// Scripts returns the watched scripts.
+func (wl *WatchList) Scripts() [][]byte {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+
+	scripts := make([][]byte, 0, len(wl.scripts))
+	for script := range wl.scripts {
+		scripts = append(scripts, []byte(script))
+	}
+	return scripts
+}
+
+// Outpoints returns the watched outpoints, keyed by their string
+// representations.
+func (wl *WatchList) Outpoints() map[string]WatchedOutpoint {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+
+	outpoints := make(map[string]WatchedOutpoint, len(wl.outpoints))
+	for outpoint, op := range wl.outpoints {
+		outpoints[outpoint] = *op
+	}
+	return outpoints
+}
+
+// FilterScripts returns the scripts to match against compact block filters:
+// the watched scripts and the scripts of the watched outpoints.
+func (wl *WatchList) FilterScripts() [][]byte {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+
+	scripts := make([][]byte, 0, len(wl.scripts)+len(wl.outpoints))
+	for script := range wl.scripts {
+		scripts = append(scripts, []byte(script))
+	}
+	for _, op := range wl.outpoints {
+		scripts = append(scripts, op.PkScript)
+	}
+	return scripts
+}
+
+// StartHeight returns the lowest start height of the watched scripts and
+// outpoints. Returns false if nothing is watched.
+func (wl *WatchList) StartHeight() (int32, bool) {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+
+	var startHeight int32
+	found := false
+	for _, height := range wl.scripts {
+		if !found || height < startHeight {
+			startHeight, found = height, true
+		}
+	}
+	for _, op := range wl.outpoints {
+		if !found || op.StartHeight < startHeight {
+			startHeight, found = op.StartHeight, true
+		}
+	}
+	return startHeight, found
+}
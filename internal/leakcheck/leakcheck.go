@@ -0,0 +1,123 @@
+// Package leakcheck provides helpers for tests that check that the goroutines
+// started by the code under test exit.
+package leakcheck
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ignoredFuncs are functions of goroutines that the Go runtime and standard
+// library start on first use and keep running, which are not leaks.
+var ignoredFuncs = []string{
+	"os/signal.signal_recv",
+	"os/signal.loop",
+	"runtime.ensureSigM",
+}
+
+// Goroutines maps the IDs of running goroutines to their stacks.
+type Goroutines map[uint64]string
+
+// Snapshot returns the goroutines that are running.
+func Snapshot() Goroutines {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	goroutines := make(Goroutines)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		// Each stack starts with "goroutine <id> [<state>]:".
+		fields := strings.Fields(string(stack))
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		id, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		goroutines[id] = string(stack)
+	}
+	return goroutines
+}
+
+// leaked returns the stacks of the goroutines in g that are not in baseline.
+// Goroutines that were started by the same go statement as a goroutine in
+// baseline that exited replaced it, such as goroutines of a service that was
+// restarted, and are not leaks.
+func (g Goroutines) leaked(baseline Goroutines) []string {
+	replaceable := make(map[string]int)
+	for id, stack := range baseline {
+		if _, running := g[id]; !running {
+			replaceable[creationSite(stack)]++
+		}
+	}
+
+	var stacks []string
+	for id, stack := range g {
+		if _, found := baseline[id]; found || ignored(stack) {
+			continue
+		}
+		if site := creationSite(stack); replaceable[site] > 0 {
+			replaceable[site]--
+			continue
+		}
+		stacks = append(stacks, stack)
+	}
+	return stacks
+}
+
+// creationSite returns the location of the go statement that started the
+// goroutine with the provided stack, or the stack itself if it has none.
+func creationSite(stack string) string {
+	// The stack ends with:
+	// created by <function> in goroutine <id>
+	// 	<file>:<line> +<offset>
+	_, site, found := strings.Cut(stack, "\ncreated by ")
+	if !found {
+		return stack
+	}
+	fn, location, _ := strings.Cut(site, "\n")
+	fn, _, _ = strings.Cut(fn, " in goroutine ")
+	location, _, _ = strings.Cut(strings.TrimSpace(location), " +")
+	return fn + " " + location
+}
+
+func ignored(stack string) bool {
+	for _, fn := range ignoredFuncs {
+		if strings.Contains(stack, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check waits up to timeout for the goroutines that were started after the
+// baseline snapshot was taken to exit, and fails the test with the stacks of
+// those that are still running. Goroutines are told apart by their IDs rather
+// than counted, so goroutines in baseline that exited only hide new goroutines
+// that were started by the same go statement.
+func Check(t testing.TB, baseline Goroutines, timeout time.Duration) {
+	t.Helper()
+
+	var leaked []string
+	for deadline := time.Now().Add(timeout); ; {
+		leaked = Snapshot().leaked(baseline)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(leaked) > 0 {
+		t.Fatalf("%d goroutines are still running:\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+	}
+}
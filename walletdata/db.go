@@ -3,6 +3,8 @@ package walletdata
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"sync"
 
 	"github.com/asdine/storm"
 	bolt "go.etcd.io/bbolt"
@@ -12,8 +14,12 @@ const (
 	metadataBktName     = "db_metadata"
 	txVersionKey        = "tx_version"
 	txIndexLastBlockKey = "tx_index_last_block"
+	txIndexedFieldsKey  = "tx_indexed_fields"
 )
 
+// ErrNotFound is returned when reading a value that is not in the database.
+var ErrNotFound = storm.ErrNotFound
+
 type privateTxIndexDBConfig[Tx any] TxIndexDBConfig[Tx]
 
 // DB is a storm-backed database for storing simple information in key-value
@@ -25,6 +31,10 @@ type DB[Tx any] struct {
 	// txIndexCfg may be nil, if the database is not intended to be used for tx
 	// indexing.
 	txIndexCfg *TxIndexDBConfig[Tx]
+	txSchema   *txSchema
+
+	unindexedLookupsMtx sync.Mutex
+	unindexedLookups    map[string]bool
 }
 
 // Ensure DB implements UserConfigDB, WalletConfigDB and TxIndexDB.
@@ -36,7 +46,13 @@ var _ TxIndexDB[struct{}] = (*DB[struct{}])(nil)
 // optional but must be provided if the database will be used for transaction
 // indexing. If txIndexCfg.txVersion is different from the version last used by
 // this database, the transactions index will be dropped and the wallet's
-// transactions will need to be re-indexed.
+// transactions will need to be re-indexed. An error is returned if the tx type
+// is not a struct with a storm ID field, if the configured tx ID field is
+// neither the storm ID nor tagged `storm:"unique"`, or if the configured block
+// height field is not a signed integer. If the tx type's indexed fields or
+// their storm index tags changed since the database was last used, the indices
+// of the indexed transactions are rebuilt, in time linear in the number of
+// indexed transactions.
 func Initialize[Tx any](dbPath string, txIndexCfg *TxIndexDBConfig[Tx]) (*DB[Tx], error) {
 	if txIndexCfg == nil {
 		db, _, err := openOrCreateDB(dbPath, 0)
@@ -47,12 +63,10 @@ func Initialize[Tx any](dbPath string, txIndexCfg *TxIndexDBConfig[Tx]) (*DB[Tx]
 	}
 
 	sampleTx := txIndexCfg.makeEmptyTx()
-
-	// TODO: Use reflection to verify that the provided txIndexCfg.uniqueTxField
-	// has a `storm:"unique"` tag. Also read all indexed fields into a slice and
-	// log a warning if tx lookup is performed using a field that is not in the
-	// indexed fields slice. Additionally, if the list of indexed fields found
-	// now differs from what was last used, reindex the database.
+	schema, err := parseTxSchema(sampleTx, txIndexCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx index config: %w", err)
+	}
 
 	latestTxVersion := txIndexCfg.txVersion
 	db, dbTxVersion, err := openOrCreateDB(dbPath, latestTxVersion)
@@ -65,6 +79,9 @@ func Initialize[Tx any](dbPath string, txIndexCfg *TxIndexDBConfig[Tx]) (*DB[Tx]
 		if err = db.Drop(sampleTx); err != nil {
 			return nil, fmt.Errorf("error deleting outdated wallet transactions: %s", err.Error())
 		}
+		if err = dropTxCursorIndex(db); err != nil {
+			return nil, fmt.Errorf("error deleting outdated tx cursor index: %s", err.Error())
+		}
 		// Reset the tx index last block value, so the db consumer knows to
 		// re-index transactions from scratch.
 		if err = db.Set(metadataBktName, txIndexLastBlockKey, 0); err != nil {
@@ -82,9 +99,35 @@ func Initialize[Tx any](dbPath string, txIndexCfg *TxIndexDBConfig[Tx]) (*DB[Tx]
 		return nil, fmt.Errorf("error initializing tx index bucket: %s", err.Error())
 	}
 
+	// Index the previously indexed transactions for cursor-based pagination
+	// if they were indexed before the tx cursor index was added.
+	if err = buildTxCursorIndex(db, txIndexCfg); err != nil {
+		return nil, fmt.Errorf("error building tx cursor index: %s", err.Error())
+	}
+
+	// Rebuild the indices of previously indexed transactions if the indexed
+	// fields or their index kinds changed since the database was last used.
+	// The tx data is not affected, so the transactions need not be re-indexed
+	// from scratch.
+	var dbIndices []string
+	err = db.Get(metadataBktName, txIndexedFieldsKey, &dbIndices)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, fmt.Errorf("error reading indexed tx fields: %s", err.Error())
+	}
+	if indices := schema.indices(); !reflect.DeepEqual(dbIndices, indices) {
+		if err = rebuildTxIndices(db, txIndexCfg); err != nil {
+			return nil, fmt.Errorf("error rebuilding tx indices: %s", err.Error())
+		}
+		if err = db.Set(metadataBktName, txIndexedFieldsKey, indices); err != nil {
+			return nil, fmt.Errorf("error saving indexed tx fields: %s", err.Error())
+		}
+	}
+
 	return &DB[Tx]{
-		db:         db,
-		txIndexCfg: txIndexCfg,
+		db:               db,
+		txIndexCfg:       txIndexCfg,
+		txSchema:         schema,
+		unindexedLookups: make(map[string]bool),
 	}, nil
 }
 
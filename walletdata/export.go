@@ -0,0 +1,359 @@
+package walletdata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/asdine/storm/q"
+)
+
+// ExportFormat is the file format that transactions are exported to.
+type ExportFormat uint8
+
+const (
+	// ExportCSV writes a header row with the column names followed by a row
+	// for each transaction.
+	ExportCSV ExportFormat = iota
+	// ExportJSON writes a JSON array with an object for each transaction,
+	// whose keys are the column names.
+	ExportJSON
+)
+
+// ExportColumn is a transaction property included in an export.
+type ExportColumn string
+
+const (
+	ExportColumnHash        ExportColumn = "hash"
+	ExportColumnBlockHeight ExportColumn = "block_height"
+	// ExportColumnTime is the time that the transaction was first seen by the
+	// wallet, in RFC 3339 format and UTC.
+	ExportColumnTime      ExportColumn = "time"
+	ExportColumnDirection ExportColumn = "direction"
+	ExportColumnType      ExportColumn = "type"
+	// ExportColumnAmount and ExportColumnFee are in the asset's smallest unit.
+	ExportColumnAmount ExportColumn = "amount"
+	ExportColumnFee    ExportColumn = "fee"
+	// ExportColumnFiatValue is the fiat value of the transaction amount at the
+	// time of the transaction. It is only exported if a FiatRateSource is
+	// provided.
+	ExportColumnFiatValue ExportColumn = "fiat_value"
+)
+
+// DefaultExportColumns are the columns exported if no columns are specified in
+// the ExportOptions.
+var DefaultExportColumns = []ExportColumn{
+	ExportColumnHash,
+	ExportColumnBlockHeight,
+	ExportColumnTime,
+	ExportColumnDirection,
+	ExportColumnType,
+	ExportColumnAmount,
+	ExportColumnFee,
+	ExportColumnFiatValue,
+}
+
+// defaultExportBatchSize is the number of transactions read from the database
+// at a time if no batch size is specified in the ExportOptions.
+const defaultExportBatchSize = 500
+
+// FiatRateSource returns the fiat value of 1 coin of the asset at the specified
+// time.
+type FiatRateSource func(t time.Time) (float64, error)
+
+// ExportOptions configures a transaction export.
+type ExportOptions struct {
+	Format ExportFormat
+	// Columns are the exported columns, in order. DefaultExportColumns are
+	// exported if no columns are specified.
+	Columns []ExportColumn
+	// Matchers filter the exported transactions. See TxTimeRangeMatcher,
+	// TxAccountMatcher and TxDirectionMatcher.
+	Matchers []q.Matcher
+	// FiatRate, if set, is used to compute the fiat value column. UnitsPerCoin
+	// is the number of the asset's smallest units in 1 coin and must be set if
+	// FiatRate is set.
+	FiatRate     FiatRateSource
+	UnitsPerCoin int64
+	// BatchSize is the number of transactions read from the database at a
+	// time. Defaults to 500.
+	BatchSize int
+}
+
+// TxTimeRangeMatcher matches transactions that were first seen by the wallet
+// from the from time up to and including the to time. A zero from or to time
+// leaves the range unbounded on that side.
+func TxTimeRangeMatcher(from, to time.Time) q.Matcher {
+	var matchers []q.Matcher
+	if !from.IsZero() {
+		matchers = append(matchers, q.Gte("Timestamp", from.Unix()))
+	}
+	if !to.IsZero() {
+		matchers = append(matchers, q.Lte("Timestamp", to.Unix()))
+	}
+	if len(matchers) == 0 {
+		return q.True()
+	}
+	return q.And(matchers...)
+}
+
+// TxAccountMatcher matches transactions that spend from or pay to the
+// specified account.
+func TxAccountMatcher(account uint32) q.Matcher {
+	return q.Or(
+		q.NewFieldMatcher("Inputs", txAccountMatcher(account)),
+		q.NewFieldMatcher("Outputs", txAccountMatcher(account)),
+	)
+}
+
+// TxDirectionMatcher matches transactions with the specified direction.
+func TxDirectionMatcher(direction TxDirection) q.Matcher {
+	return q.Eq("Direction", direction)
+}
+
+// txAccountMatcher is a q.FieldMatcher that matches a Transaction's Inputs or
+// Outputs if any of them belongs to the wallet account.
+type txAccountMatcher uint32
+
+func (account txAccountMatcher) MatchField(v interface{}) (bool, error) {
+	switch field := v.(type) {
+	case []*TxInput:
+		for _, in := range field {
+			if in.IsMine && in.Account == uint32(account) {
+				return true, nil
+			}
+		}
+	case []*TxOutput:
+		for _, out := range field {
+			if out.IsMine && out.Account == uint32(account) {
+				return true, nil
+			}
+		}
+	default:
+		return false, fmt.Errorf("cannot match account in %T", v)
+	}
+	return false, nil
+}
+
+// ExportTransactions writes the indexed transactions that match opts.Matchers
+// to w in the format and with the columns specified in opts. Mined transactions
+// are written in ascending order of block height and tx hash, followed by the
+// unmined transactions. Transactions are read from db in batches using tx
+// cursors, so that the complete history is never loaded into memory and no
+// transaction is skipped or repeated if transactions are indexed during the
+// export. Returns the number of exported transactions.
+func ExportTransactions(db TxIndexDB[Transaction], w io.Writer, opts *ExportOptions) (int, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultExportColumns
+	}
+	if opts.FiatRate == nil {
+		// Drop the fiat value column, since it can't be computed.
+		exportedColumns := make([]ExportColumn, 0, len(columns))
+		for _, column := range columns {
+			if column != ExportColumnFiatValue {
+				exportedColumns = append(exportedColumns, column)
+			}
+		}
+		columns = exportedColumns
+	} else if opts.UnitsPerCoin <= 0 {
+		return 0, fmt.Errorf("UnitsPerCoin is required to compute fiat values")
+	}
+	for _, column := range columns {
+		if !column.isValid() {
+			return 0, fmt.Errorf("unknown export column %q", column)
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultExportBatchSize
+	}
+
+	var writer txExportWriter
+	switch opts.Format {
+	case ExportCSV:
+		writer = &csvTxExportWriter{w: csv.NewWriter(w), columns: columns}
+	case ExportJSON:
+		writer = &jsonTxExportWriter{w: w, columns: columns}
+	default:
+		return 0, fmt.Errorf("unknown export format %d", uint8(opts.Format))
+	}
+
+	if err := writer.start(); err != nil {
+		return 0, err
+	}
+	var exported int
+	exportTxs := func(txs []*Transaction, mined bool) (bool, error) {
+		for _, tx := range txs {
+			if (tx.BlockHeight >= 0) != mined {
+				return false, nil
+			}
+			values, err := exportValues(tx, columns, opts)
+			if err != nil {
+				return false, err
+			}
+			if err = writer.write(values); err != nil {
+				return false, err
+			}
+			exported++
+		}
+		return true, nil
+	}
+
+	// Unmined transactions have a block height of -1 and come first in tx
+	// cursor order, so start the mined transactions after them and read the
+	// unmined transactions from the start until the first mined transaction.
+	for _, mined := range []bool{true, false} {
+		var cursor *TxCursor
+		if mined {
+			cursor = &TxCursor{BlockHeight: 0, TxID: ""}
+		}
+		for {
+			txs, nextCursor, err := db.FindTransactionsAfter(cursor, batchSize, false, opts.Matchers...)
+			if err != nil {
+				return exported, fmt.Errorf("error reading transactions: %w", err)
+			}
+			more, err := exportTxs(txs, mined)
+			if err != nil {
+				return exported, err
+			}
+			if !more || nextCursor == nil {
+				break
+			}
+			cursor = nextCursor
+		}
+	}
+	return exported, writer.end()
+}
+
+// isValid returns true if column is one of the known export columns.
+func (column ExportColumn) isValid() bool {
+	for _, c := range DefaultExportColumns {
+		if column == c {
+			return true
+		}
+	}
+	return false
+}
+
+// exportValues returns the values of tx for the specified columns.
+func exportValues(tx *Transaction, columns []ExportColumn, opts *ExportOptions) ([]interface{}, error) {
+	txTime := time.Unix(tx.Timestamp, 0).UTC()
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case ExportColumnHash:
+			values[i] = tx.Hash
+		case ExportColumnBlockHeight:
+			values[i] = tx.BlockHeight
+		case ExportColumnTime:
+			values[i] = txTime.Format(time.RFC3339)
+		case ExportColumnDirection:
+			values[i] = tx.Direction.String()
+		case ExportColumnType:
+			values[i] = tx.Type.String()
+		case ExportColumnAmount:
+			values[i] = tx.Amount
+		case ExportColumnFee:
+			values[i] = tx.Fee
+		case ExportColumnFiatValue:
+			rate, err := opts.FiatRate(txTime)
+			if err != nil {
+				return nil, fmt.Errorf("error getting fiat rate for tx %s: %w", tx.Hash, err)
+			}
+			values[i] = float64(tx.Amount) / float64(opts.UnitsPerCoin) * rate
+		}
+	}
+	return values, nil
+}
+
+// txExportWriter writes exported transactions in a specific format.
+type txExportWriter interface {
+	start() error
+	write(values []interface{}) error
+	end() error
+}
+
+type csvTxExportWriter struct {
+	w       *csv.Writer
+	columns []ExportColumn
+}
+
+func (cw *csvTxExportWriter) start() error {
+	header := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		header[i] = string(column)
+	}
+	return cw.w.Write(header)
+}
+
+func (cw *csvTxExportWriter) write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvTxExportWriter) end() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonTxExportWriter struct {
+	w       io.Writer
+	columns []ExportColumn
+	wrote   bool
+}
+
+func (jw *jsonTxExportWriter) start() error {
+	_, err := io.WriteString(jw.w, "[")
+	return err
+}
+
+// write writes values as a JSON object whose keys are in the order of the
+// exported columns.
+func (jw *jsonTxExportWriter) write(values []interface{}) error {
+	buf := make([]byte, 0, 256)
+	if jw.wrote {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, "\n{"...)
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(string(jw.columns[i]))
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		if v, isFloat := value.(float64); isFloat {
+			buf = strconv.AppendFloat(buf, v, 'f', 2, 64)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, encoded...)
+	}
+	buf = append(buf, '}')
+	jw.wrote = true
+	_, err := jw.w.Write(buf)
+	return err
+}
+
+func (jw *jsonTxExportWriter) end() error {
+	_, err := io.WriteString(jw.w, "\n]\n")
+	return err
+}
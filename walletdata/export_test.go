@@ -0,0 +1,178 @@
+package walletdata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/q"
+)
+
+// exportedRows parses the rows of an export in the specified format into the
+// string values of the columns, in order.
+func exportedRows(t *testing.T, format ExportFormat, columns []ExportColumn, export []byte) [][]string {
+	t.Helper()
+	switch format {
+	case ExportCSV:
+		records, err := csv.NewReader(bytes.NewReader(export)).ReadAll()
+		if err != nil {
+			t.Fatalf("error parsing csv export: %v", err)
+		}
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = string(column)
+		}
+		if len(records) == 0 || !reflect.DeepEqual(records[0], header) {
+			t.Fatalf("expected csv header %v, got %v", header, records)
+		}
+		return records[1:]
+
+	case ExportJSON:
+		var objects []map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(export))
+		dec.UseNumber()
+		if err := dec.Decode(&objects); err != nil {
+			t.Fatalf("error parsing json export: %v", err)
+		}
+		var rows [][]string
+		for _, obj := range objects {
+			if len(obj) != len(columns) {
+				t.Fatalf("expected json object with %d keys, got %v", len(columns), obj)
+			}
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				value, found := obj[string(column)]
+				if !found {
+					t.Fatalf("json object %v has no %s key", obj, column)
+				}
+				row[i] = fmt.Sprint(value)
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+	t.Fatalf("unknown export format %d", format)
+	return nil
+}
+
+// TestExportTransactionsPaging checks that exports that read more transactions
+// than fit in a batch write every mined transaction in cursor order followed by
+// every unmined transaction exactly once, and that the CSV and JSON exports
+// have the same rows whatever the batch size.
+func TestExportTransactionsPaging(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "walletdata.db"), TransactionIndexDBConfig())
+
+	// Interleave the hashes of mined and unmined txs and put several txs at
+	// the same height, so that batches end between txs at the same height and
+	// between the mined and unmined txs.
+	heights := []int32{-1, 3, 3, -1, 0, 7, -1, 3, 12, -1, 7, -1, 0, 3, 12, 3, -1}
+	var minedTxs, unminedTxs []*Transaction
+	for i, height := range heights {
+		outputs := []*TxOutput{{Index: 0, Amount: int64(i+1) * 1e6, IsMine: true, Account: uint32(i % 2)}}
+		tx := NewTransaction(fmt.Sprintf("tx%02d", i), height, int64(1700000000+i*600), TxTypeRegular, 0, nil, outputs)
+		if _, err := db.IndexTransaction(tx); err != nil {
+			t.Fatalf("error indexing tx %s: %v", tx.Hash, err)
+		}
+		if height < 0 {
+			unminedTxs = append(unminedTxs, tx)
+		} else {
+			minedTxs = append(minedTxs, tx)
+		}
+	}
+	sort.Slice(minedTxs, func(i, j int) bool {
+		if minedTxs[i].BlockHeight != minedTxs[j].BlockHeight {
+			return minedTxs[i].BlockHeight < minedTxs[j].BlockHeight
+		}
+		return minedTxs[i].Hash < minedTxs[j].Hash
+	})
+	sort.Slice(unminedTxs, func(i, j int) bool { return unminedTxs[i].Hash < unminedTxs[j].Hash })
+
+	fiatRate := func(time.Time) (float64, error) { return 12.5, nil }
+	expectedRows := func(txs []*Transaction) [][]string {
+		var rows [][]string
+		for _, tx := range txs {
+			rows = append(rows, []string{
+				tx.Hash,
+				fmt.Sprint(tx.BlockHeight),
+				time.Unix(tx.Timestamp, 0).UTC().Format(time.RFC3339),
+				fmt.Sprint(tx.Amount),
+				fmt.Sprintf("%.2f", float64(tx.Amount)/1e8*12.5),
+			})
+		}
+		return rows
+	}
+	columns := []ExportColumn{ExportColumnHash, ExportColumnBlockHeight, ExportColumnTime, ExportColumnAmount, ExportColumnFiatValue}
+
+	var account1Txs []*Transaction
+	for _, tx := range append(append([]*Transaction{}, minedTxs...), unminedTxs...) {
+		if tx.Outputs[0].Account == 1 {
+			account1Txs = append(account1Txs, tx)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		matchers     []q.Matcher
+		expectedRows [][]string
+	}{{
+		name:         "all txs",
+		expectedRows: expectedRows(append(append([]*Transaction{}, minedTxs...), unminedTxs...)),
+	}, {
+		name:         "account matcher",
+		matchers:     []q.Matcher{TxAccountMatcher(1)},
+		expectedRows: expectedRows(account1Txs),
+	}, {
+		name:     "unmined txs",
+		matchers: []q.Matcher{q.Lt("BlockHeight", int32(0))},
+		// Batches read after the unmined txs must not return them again.
+		expectedRows: expectedRows(unminedTxs),
+	}}
+
+	// Batch sizes of 1 up to one more than the number of txs, so that batches
+	// end at every tx and the default batch size reads every tx in one batch.
+	for _, test := range tests {
+		for batchSize := 1; batchSize <= len(heights)+1; batchSize++ {
+			for _, format := range []ExportFormat{ExportCSV, ExportJSON} {
+				t.Run(fmt.Sprintf("%s/batch size %d/format %d", test.name, batchSize, format), func(t *testing.T) {
+					opts := &ExportOptions{
+						Format:       format,
+						Columns:      columns,
+						Matchers:     test.matchers,
+						FiatRate:     fiatRate,
+						UnitsPerCoin: 1e8,
+						BatchSize:    batchSize,
+					}
+					var buf bytes.Buffer
+					exported, err := ExportTransactions(db, &buf, opts)
+					if err != nil {
+						t.Fatalf("error exporting txs: %v", err)
+					}
+					if exported != len(test.expectedRows) {
+						t.Fatalf("expected %d exported txs, got %d", len(test.expectedRows), exported)
+					}
+					rows := exportedRows(t, format, columns, buf.Bytes())
+					if !reflect.DeepEqual(rows, test.expectedRows) {
+						t.Fatalf("expected rows\n%v\ngot\n%v", test.expectedRows, rows)
+					}
+
+					// The output is identical to reading every tx in a
+					// single batch.
+					opts.BatchSize = 0
+					var singleBatch bytes.Buffer
+					if _, err := ExportTransactions(db, &singleBatch, opts); err != nil {
+						t.Fatalf("error exporting txs: %v", err)
+					}
+					if !bytes.Equal(buf.Bytes(), singleBatch.Bytes()) {
+						t.Fatalf("export with batch size %d differs from a single batch export:\n%s\n%s", batchSize, buf.Bytes(), singleBatch.Bytes())
+					}
+				})
+			}
+		}
+	}
+}
@@ -28,4 +28,7 @@ type TxIndexDB[T any] interface {
 	FindTransaction(fieldName string, fieldValue interface{}) (*T, error)
 	FindTransactions(offset, limit int, sort *SORT, matchers ...q.Matcher) ([]*T, error)
 	CountTransactions(matchers ...q.Matcher) (int, error)
+	TxCursorFor(tx *T) (*TxCursor, error)
+	FindTransactionsAfter(cursor *TxCursor, limit int, reversed bool, matchers ...q.Matcher) ([]*T, *TxCursor, error)
+	IterateTransactions(reversed bool, batchSize int, matchers ...q.Matcher) *TxIterator[T]
 }
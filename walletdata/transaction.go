@@ -0,0 +1,253 @@
+package walletdata
+
+import "fmt"
+
+// TransactionVersion is the version of the Transaction type. Indexed
+// transactions are dropped and must be re-indexed when this version changes.
+const TransactionVersion uint32 = 1
+
+// TxDirection describes the effect of a transaction on the wallet's balance.
+type TxDirection uint8
+
+const (
+	// TxDirectionSent is for transactions that pay to addresses that are not
+	// owned by the wallet.
+	TxDirectionSent TxDirection = iota
+	// TxDirectionReceived is for transactions that pay to the wallet without
+	// spending any of the wallet's outputs.
+	TxDirectionReceived
+	// TxDirectionTransferred is for transactions that spend the wallet's
+	// outputs and only pay to the wallet's addresses.
+	TxDirectionTransferred
+)
+
+// String returns a human-readable representation of the tx direction.
+func (d TxDirection) String() string {
+	switch d {
+	case TxDirectionSent:
+		return "sent"
+	case TxDirectionReceived:
+		return "received"
+	case TxDirectionTransferred:
+		return "transferred"
+	default:
+		return fmt.Sprintf("unknown tx direction %d", uint8(d))
+	}
+}
+
+// TxType is the type of a transaction. The stake transaction types only apply
+// to assets that support staking.
+type TxType uint8
+
+const (
+	TxTypeRegular TxType = iota
+	TxTypeCoinbase
+	TxTypeTicketPurchase
+	TxTypeVote
+	TxTypeRevocation
+	// TxTypeMix is for CoinShuffle++ mix transactions, which have inputs and
+	// outputs that belong to other wallets.
+	TxTypeMix
+)
+
+// String returns a human-readable representation of the tx type.
+func (t TxType) String() string {
+	switch t {
+	case TxTypeRegular:
+		return "regular"
+	case TxTypeCoinbase:
+		return "coinbase"
+	case TxTypeTicketPurchase:
+		return "ticket purchase"
+	case TxTypeVote:
+		return "vote"
+	case TxTypeRevocation:
+		return "revocation"
+	case TxTypeMix:
+		return "mix"
+	default:
+		return fmt.Sprintf("unknown tx type %d", uint8(t))
+	}
+}
+
+// TxInput is an input of a Transaction. Amount and Account are only known for
+// inputs that spend the wallet's outputs.
+type TxInput struct {
+	PreviousOutpoint string
+	Amount           int64
+	Account          uint32
+	IsMine           bool
+}
+
+// TxOutput is an output of a Transaction. Address is empty if the output script
+// does not pay to exactly one address. Account and IsChange are only set for
+// outputs that pay to the wallet.
+type TxOutput struct {
+	Index    uint32
+	Amount   int64
+	Address  string
+	Account  uint32
+	IsMine   bool
+	IsChange bool
+}
+
+// Transaction is an asset-agnostic wallet transaction that can be indexed in a
+// TxIndexDB configured with TransactionIndexDBConfig. Amounts are in the
+// asset's smallest unit.
+type Transaction struct {
+	Hash string `storm:"id,unique"`
+	// BlockHeight is -1 for unmined transactions.
+	BlockHeight int32 `storm:"index"`
+	// Timestamp is the unix time that the transaction was first seen by the
+	// wallet.
+	Timestamp int64       `storm:"index"`
+	Direction TxDirection `storm:"index"`
+	Type      TxType      `storm:"index"`
+	// Amount is the amount sent, received or transferred, depending on the
+	// direction of the transaction.
+	Amount int64
+	// Fee is 0 if the wallet does not own all of the transaction's inputs.
+	Fee     int64
+	Inputs  []*TxInput
+	Outputs []*TxOutput
+}
+
+// NewTransaction creates a Transaction and determines its direction and amount
+// from the wallet's inputs and outputs. The amount of a sent transaction
+// excludes the fee and the change returned to the wallet. The amount of a
+// transferred transaction is the total of the wallet's non-change outputs,
+// or of all outputs if they are all change. Mix transactions are considered
+// transferred and their amount is the total of the wallet's mixed outputs.
+// Stake transactions are classified by their type: ticket purchases are
+// transferred and their amount is the ticket price, votes are received and
+// their amount is the stake reward, and revocations are received and their
+// amount is the returned ticket funds.
+func NewTransaction(hash string, blockHeight int32, timestamp int64, txType TxType, fee int64, inputs []*TxInput, outputs []*TxOutput) *Transaction {
+	var debit, credit, nonChangeCredit int64
+	for _, in := range inputs {
+		if in.IsMine {
+			debit += in.Amount
+		}
+	}
+	allOutputsMine := true
+	for _, out := range outputs {
+		if !out.IsMine {
+			allOutputsMine = false
+			continue
+		}
+		credit += out.Amount
+		if !out.IsChange {
+			nonChangeCredit += out.Amount
+		}
+	}
+
+	tx := &Transaction{
+		Hash:        hash,
+		BlockHeight: blockHeight,
+		Timestamp:   timestamp,
+		Type:        txType,
+		Fee:         fee,
+		Inputs:      inputs,
+		Outputs:     outputs,
+	}
+	switch {
+	case txType == TxTypeTicketPurchase:
+		// The ticket price is the value of the first output.
+		tx.Direction = TxDirectionTransferred
+		for _, out := range outputs {
+			if out.Index == 0 {
+				tx.Amount = out.Amount
+				break
+			}
+		}
+	case txType == TxTypeVote:
+		// The vote spends the ticket and returns its price along with the
+		// reward.
+		tx.Direction = TxDirectionReceived
+		tx.Amount = credit - debit
+	case txType == TxTypeRevocation:
+		tx.Direction = TxDirectionReceived
+		tx.Amount = credit
+	case txType == TxTypeMix:
+		tx.Direction = TxDirectionTransferred
+		tx.Amount = nonChangeCredit
+	case debit == 0:
+		tx.Direction = TxDirectionReceived
+		tx.Amount = credit
+	case allOutputsMine:
+		tx.Direction = TxDirectionTransferred
+		tx.Amount = nonChangeCredit
+		if tx.Amount == 0 {
+			tx.Amount = credit
+		}
+	default:
+		tx.Direction = TxDirectionSent
+		tx.Amount = debit - credit - fee
+	}
+	return tx
+}
+
+// MyTxInput identifies a transaction input that spends an output of the wallet.
+type MyTxInput struct {
+	Index   uint32
+	Amount  int64
+	Account uint32
+}
+
+// MyTxOutput identifies a transaction output that pays to the wallet.
+type MyTxOutput struct {
+	Index    uint32
+	Account  uint32
+	IsChange bool
+}
+
+// TxSummary describes a wallet transaction as reported by a wallet backend. It
+// is converted to a Transaction with its Transaction method, so that the
+// wallet's inputs and outputs are classified the same way for every asset.
+type TxSummary struct {
+	Hash        string
+	BlockHeight int32
+	Timestamp   int64
+	Type        TxType
+	Fee         int64
+	// Inputs and Outputs are all of the transaction's inputs and outputs.
+	// Only the PreviousOutpoint of the inputs and the Index, Amount and
+	// Address of the outputs need to be set.
+	Inputs  []*TxInput
+	Outputs []*TxOutput
+	// MyInputs and MyOutputs are the transaction's inputs and outputs that
+	// belong to the wallet.
+	MyInputs  []MyTxInput
+	MyOutputs []MyTxOutput
+}
+
+// Transaction marks the wallet's inputs and outputs of the summarized
+// transaction and creates a Transaction from them with NewTransaction.
+func (s *TxSummary) Transaction() *Transaction {
+	for _, in := range s.MyInputs {
+		if int(in.Index) >= len(s.Inputs) {
+			continue
+		}
+		input := s.Inputs[in.Index]
+		input.Amount = in.Amount
+		input.Account = in.Account
+		input.IsMine = true
+	}
+	for _, out := range s.MyOutputs {
+		if int(out.Index) >= len(s.Outputs) {
+			continue
+		}
+		output := s.Outputs[out.Index]
+		output.Account = out.Account
+		output.IsMine = true
+		output.IsChange = out.IsChange
+	}
+	return NewTransaction(s.Hash, s.BlockHeight, s.Timestamp, s.Type, s.Fee, s.Inputs, s.Outputs)
+}
+
+// TransactionIndexDBConfig returns a TxIndexDBConfig for indexing Transactions.
+func TransactionIndexDBConfig() *TxIndexDBConfig[Transaction] {
+	return NewTxIndexDBConfig(TransactionVersion, "Hash", "BlockHeight", func() *Transaction {
+		return &Transaction{}
+	}, nil)
+}
@@ -0,0 +1,332 @@
+package walletdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/codec"
+	"github.com/asdine/storm/q"
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultTxIteratorBatchSize is the number of transactions read from the
+// database at a time by a TxIterator if no batch size is specified.
+const defaultTxIteratorBatchSize = 100
+
+// txCursorBktName is the bucket that indexes transactions by block height and
+// tx ID, for cursor-based pagination. Keys are created by txCursorKey and
+// values are the encoded tx IDs.
+const txCursorBktName = "tx_cursor_index"
+
+// TxCursor marks the position of a transaction in the order of block height
+// and tx ID that is used for cursor-based pagination. Unlike offsets, cursors
+// remain valid when transactions are indexed between page reads. A cursor can
+// be persisted to resume pagination later.
+type TxCursor struct {
+	BlockHeight int32
+	TxID        interface{}
+}
+
+// TxCursorFor returns the cursor that marks the position of tx.
+func (db *DB[Tx]) TxCursorFor(tx *Tx) (*TxCursor, error) {
+	if db.txIndexCfg == nil {
+		return nil, ErrTxIndexNotSupported
+	}
+
+	txValue := reflect.ValueOf(tx).Elem()
+	blockHeight := txValue.FieldByName(db.txIndexCfg.txBlockHeightField)
+	if !blockHeight.CanInt() {
+		return nil, fmt.Errorf("tx block height field %s is not an integer", db.txIndexCfg.txBlockHeightField)
+	}
+	return &TxCursor{
+		BlockHeight: int32(blockHeight.Int()),
+		TxID:        txValue.FieldByName(db.txIndexCfg.txIDField).Interface(),
+	}, nil
+}
+
+// FindTransactionsAfter returns up to limit transactions that match the
+// specified criteria and come after cursor in ascending order of block height
+// and tx ID, or in descending order if reversed is true. A nil cursor starts
+// from the first transaction. The returned cursor marks the position of the
+// last returned transaction and should be passed to the next call to read the
+// next page. The returned cursor is nil if there are no more transactions.
+//
+// Transactions are read in order from the tx cursor index, so only the
+// transactions up to the last returned one are read from the database. String
+// and integer tx IDs are ordered by value, other tx IDs by their encoding.
+func (db *DB[Tx]) FindTransactionsAfter(cursor *TxCursor, limit int, reversed bool, matchers ...q.Matcher) ([]*Tx, *TxCursor, error) {
+	if db.txIndexCfg == nil {
+		return nil, nil, ErrTxIndexNotSupported
+	}
+
+	var cursorKey []byte
+	if cursor != nil {
+		var err error
+		if cursorKey, err = txCursorKey(cursor.BlockHeight, cursor.TxID, db.db.Codec()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var txs []*Tx
+	err := db.db.Bolt.View(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket([]byte(txCursorBktName))
+		if bucket == nil {
+			return nil
+		}
+		node := db.db.WithTransaction(btx)
+
+		c := bucket.Cursor()
+		var k, v []byte
+		switch {
+		case cursorKey == nil && reversed:
+			k, v = c.Last()
+		case cursorKey == nil:
+			k, v = c.First()
+		case reversed:
+			// Seek finds the first key at or after the cursor.
+			if k, _ = c.Seek(cursorKey); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		default:
+			if k, v = c.Seek(cursorKey); bytes.Equal(k, cursorKey) {
+				k, v = c.Next()
+			}
+		}
+
+		for ; k != nil && (limit <= 0 || len(txs) < limit); k, v = nextTxCursorKey(c, reversed) {
+			if v == nil {
+				// Skip storm's metadata bucket.
+				continue
+			}
+			txID, err := db.decodeTxID(v)
+			if err != nil {
+				return err
+			}
+			tx := db.txIndexCfg.makeEmptyTx()
+			if err = node.One(db.txIndexCfg.txIDField, txID, tx); err != nil {
+				return fmt.Errorf("error reading tx %v: %w", txID, err)
+			}
+			matched, err := q.And(matchers...).Match(tx)
+			if err != nil {
+				return err
+			}
+			if matched {
+				txs = append(txs, tx)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(txs) == 0 || limit <= 0 || len(txs) < limit {
+		// All the remaining transactions were read.
+		return txs, nil, nil
+	}
+
+	nextCursor, err := db.TxCursorFor(txs[len(txs)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs, nextCursor, nil
+}
+
+// nextTxCursorKey moves c to the next key of the tx cursor index, or to the
+// previous key if reversed is true.
+func nextTxCursorKey(c *bolt.Cursor, reversed bool) ([]byte, []byte) {
+	if reversed {
+		return c.Prev()
+	}
+	return c.Next()
+}
+
+// decodeTxID decodes a tx ID stored in the tx cursor index.
+func (db *DB[Tx]) decodeTxID(encoded []byte) (interface{}, error) {
+	sampleTx := reflect.ValueOf(db.txIndexCfg.makeEmptyTx()).Elem()
+	txID := reflect.New(sampleTx.FieldByName(db.txIndexCfg.txIDField).Type())
+	if err := db.db.Codec().Unmarshal(encoded, txID.Interface()); err != nil {
+		return nil, fmt.Errorf("error decoding tx ID: %w", err)
+	}
+	return txID.Elem().Interface(), nil
+}
+
+// txCursorKey returns the key of a transaction in the tx cursor index, which
+// sorts the keys of transactions in order of block height and tx ID. The key
+// is the block height with its sign bit flipped so that negative heights sort
+// first, in big-endian order, followed by the encoded tx ID.
+func txCursorKey(blockHeight int32, txID interface{}, codec codec.MarshalUnmarshaler) ([]byte, error) {
+	key := make([]byte, 4, 12)
+	binary.BigEndian.PutUint32(key, uint32(blockHeight)^(1<<31))
+
+	v := reflect.ValueOf(txID)
+	switch v.Kind() {
+	case reflect.String:
+		return append(key, v.String()...), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.BigEndian.AppendUint64(key, uint64(v.Int())^(1<<63)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return binary.BigEndian.AppendUint64(key, v.Uint()), nil
+	case reflect.Slice:
+		if b, ok := txID.([]byte); ok {
+			return append(key, b...), nil
+		}
+	}
+	encoded, err := codec.Marshal(txID)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding tx ID: %w", err)
+	}
+	return append(key, encoded...), nil
+}
+
+// indexTxCursor adds tx to the tx cursor index in the storm transaction node,
+// replacing the entry of oldTx if it is not nil.
+func (db *DB[Tx]) indexTxCursor(node storm.Node, tx, oldTx *Tx) error {
+	if oldTx != nil {
+		if err := db.deleteTxCursor(node, oldTx); err != nil {
+			return err
+		}
+	}
+	cursor, err := db.TxCursorFor(tx)
+	if err != nil {
+		return err
+	}
+	key, err := txCursorKey(cursor.BlockHeight, cursor.TxID, node.Codec())
+	if err != nil {
+		return err
+	}
+	return node.Set(txCursorBktName, key, cursor.TxID)
+}
+
+// deleteTxCursor removes tx from the tx cursor index in the storm transaction
+// node.
+func (db *DB[Tx]) deleteTxCursor(node storm.Node, tx *Tx) error {
+	cursor, err := db.TxCursorFor(tx)
+	if err != nil {
+		return err
+	}
+	key, err := txCursorKey(cursor.BlockHeight, cursor.TxID, node.Codec())
+	if err != nil {
+		return err
+	}
+	return ignoreStormNotFoundError(node.Delete(txCursorBktName, key))
+}
+
+// dropTxCursorIndex deletes the tx cursor index in the storm transaction node.
+func dropTxCursorIndex(node storm.Node) error {
+	err := node.Drop(txCursorBktName)
+	if err == bolt.ErrBucketNotFound {
+		return nil
+	}
+	return err
+}
+
+// buildTxCursorIndex creates the tx cursor index of the indexed transactions if
+// it does not exist, such as in databases created before the index was added.
+func buildTxCursorIndex[Tx any](db *storm.DB, txIndexCfg *TxIndexDBConfig[Tx]) error {
+	return db.Bolt.Update(func(btx *bolt.Tx) error {
+		if btx.Bucket([]byte(txCursorBktName)) != nil {
+			return nil
+		}
+		bucket, err := btx.CreateBucket([]byte(txCursorBktName))
+		if err != nil {
+			return err
+		}
+
+		cursorDB := &DB[Tx]{db: db, txIndexCfg: txIndexCfg}
+		node := db.WithTransaction(btx)
+		err = node.Select().Each(txIndexCfg.makeEmptyTx(), func(record interface{}) error {
+			cursor, err := cursorDB.TxCursorFor(record.(*Tx))
+			if err != nil {
+				return err
+			}
+			key, err := txCursorKey(cursor.BlockHeight, cursor.TxID, db.Codec())
+			if err != nil {
+				return err
+			}
+			value, err := db.Codec().Marshal(cursor.TxID)
+			if err != nil {
+				return err
+			}
+			return bucket.Put(key, value)
+		})
+		return ignoreStormNotFoundError(err)
+	})
+}
+
+// IterateTransactions returns an iterator over the transactions that match the
+// specified criteria, in ascending order of block height and tx ID or in
+// descending order if reversed is true. Transactions are read from the
+// database in batches of batchSize, 100 if batchSize is not positive.
+func (db *DB[Tx]) IterateTransactions(reversed bool, batchSize int, matchers ...q.Matcher) *TxIterator[Tx] {
+	if batchSize <= 0 {
+		batchSize = defaultTxIteratorBatchSize
+	}
+	return &TxIterator[Tx]{
+		db:        db,
+		reversed:  reversed,
+		batchSize: batchSize,
+		matchers:  matchers,
+	}
+}
+
+// TxIterator streams transactions from a tx index database without loading
+// all of them into memory. Call Next to advance the iterator and Tx to read
+// the current transaction:
+//
+//	it := db.IterateTransactions(false, 0)
+//	for it.Next() {
+//		tx := it.Tx()
+//	}
+//	if err := it.Err(); err != nil {
+//		// Handle the error.
+//	}
+type TxIterator[Tx any] struct {
+	db        *DB[Tx]
+	reversed  bool
+	batchSize int
+	matchers  []q.Matcher
+
+	cursor *TxCursor
+	batch  []*Tx
+	pos    int
+	done   bool
+	err    error
+}
+
+// Next advances the iterator to the next transaction. It returns false when
+// there are no more transactions or an error occurred. Use Err to check for
+// errors.
+func (it *TxIterator[Tx]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos+1 < len(it.batch) {
+		it.pos++
+		return true
+	}
+	if it.done {
+		it.batch = nil
+		return false
+	}
+
+	it.batch, it.cursor, it.err = it.db.FindTransactionsAfter(it.cursor, it.batchSize, it.reversed, it.matchers...)
+	it.pos = 0
+	it.done = it.cursor == nil
+	return it.err == nil && len(it.batch) > 0
+}
+
+// Tx returns the current transaction. It must only be called after a call to
+// Next returns true.
+func (it *TxIterator[Tx]) Tx() *Tx {
+	return it.batch[it.pos]
+}
+
+// Err returns the error that ended iteration, if any.
+func (it *TxIterator[Tx]) Err() error {
+	return it.err
+}
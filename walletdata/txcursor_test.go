@@ -0,0 +1,307 @@
+package walletdata
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/asdine/storm/codec/json"
+	"github.com/asdine/storm/q"
+)
+
+// cursorTestTx is a tx type with a string tx ID for the tx cursor tests.
+type cursorTestTx struct {
+	ID     string `storm:"id"`
+	Height int32  `storm:"index"`
+}
+
+func cursorTestTxIndexDBConfig() *TxIndexDBConfig[cursorTestTx] {
+	return NewTxIndexDBConfig(1, "ID", "Height", func() *cursorTestTx {
+		return &cursorTestTx{}
+	}, nil)
+}
+
+// openTestDB opens the database at dbPath and closes it when the test ends.
+func openTestDB[Tx any](t *testing.T, dbPath string, cfg *TxIndexDBConfig[Tx]) *DB[Tx] {
+	t.Helper()
+	db, err := Initialize(dbPath, cfg)
+	if err != nil {
+		t.Fatalf("error initializing db: %v", err)
+	}
+	t.Cleanup(func() { db.db.Close() })
+	return db
+}
+
+// newCursorTestDB creates a database in a temporary directory and indexes txs
+// in it.
+func newCursorTestDB(t *testing.T, txs []*cursorTestTx) *DB[cursorTestTx] {
+	t.Helper()
+	db := openTestDB(t, filepath.Join(t.TempDir(), "walletdata.db"), cursorTestTxIndexDBConfig())
+	for _, tx := range txs {
+		if _, err := db.IndexTransaction(tx); err != nil {
+			t.Fatalf("error indexing tx %s: %v", tx.ID, err)
+		}
+	}
+	return db
+}
+
+// txIDs returns the IDs of txs.
+func txIDs(txs []*cursorTestTx) []string {
+	var ids []string
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	return ids
+}
+
+func TestTxCursorKeyOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		heightA      int32
+		txIDA        interface{}
+		heightB      int32
+		txIDB        interface{}
+		expectedSign int
+	}{
+		{"unmined before genesis", -1, "b", 0, "a", -1},
+		{"unmined before mined", -1, "z", 100, "a", -1},
+		{"lowest before highest height", -1 << 31, "a", 1<<31 - 1, "a", -1},
+		{"height byte boundary", 255, "b", 256, "a", -1},
+		{"equal heights, string IDs", 10, "abc", 10, "abd", -1},
+		{"equal heights, string ID prefix", 10, "ab", 10, "abc", -1},
+		{"equal heights, equal IDs", 10, "abc", 10, "abc", 0},
+		{"equal heights, negative int IDs", 10, -5, 10, 3, -1},
+		{"equal heights, int IDs", 10, 9, 10, 256, -1},
+		{"equal heights, uint IDs", 10, uint32(255), 10, uint32(256), -1},
+		{"equal heights, byte IDs", 10, []byte{1, 2}, 10, []byte{1, 3}, -1},
+		{"higher height, lower ID", 11, "a", 10, "z", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keyA, err := txCursorKey(test.heightA, test.txIDA, json.Codec)
+			if err != nil {
+				t.Fatalf("error creating key: %v", err)
+			}
+			keyB, err := txCursorKey(test.heightB, test.txIDB, json.Codec)
+			if err != nil {
+				t.Fatalf("error creating key: %v", err)
+			}
+			if sign := bytes.Compare(keyA, keyB); sign != test.expectedSign {
+				t.Fatalf("expected key %x to compare %d to key %x, got %d", keyA, test.expectedSign, keyB, sign)
+			}
+		})
+	}
+}
+
+func TestFindTransactionsAfter(t *testing.T) {
+	// The txs in ascending cursor order: unmined txs first, then equal
+	// heights ordered by tx ID.
+	orderedTxs := []*cursorTestTx{
+		{ID: "u1", Height: -1},
+		{ID: "u2", Height: -1},
+		{ID: "a", Height: 0},
+		{ID: "b", Height: 5},
+		{ID: "c", Height: 5},
+		{ID: "d", Height: 5},
+		{ID: "a7", Height: 7},
+		{ID: "e", Height: 300},
+	}
+
+	// Index the txs out of order.
+	txs := make([]*cursorTestTx, len(orderedTxs))
+	copy(txs, orderedTxs)
+	sort.Slice(txs, func(i, j int) bool { return txs[i].ID > txs[j].ID })
+	db := newCursorTestDB(t, txs)
+
+	tests := []struct {
+		name        string
+		cursor      *TxCursor
+		limit       int
+		reversed    bool
+		matchers    []q.Matcher
+		expectedIDs []string
+		// expectedCursor is the position of the last returned tx, nil if
+		// there are no more txs.
+		expectedCursor *TxCursor
+	}{{
+		name:           "empty cursor",
+		limit:          3,
+		expectedIDs:    []string{"u1", "u2", "a"},
+		expectedCursor: &TxCursor{BlockHeight: 0, TxID: "a"},
+	}, {
+		name:           "empty cursor reversed",
+		limit:          2,
+		reversed:       true,
+		expectedIDs:    []string{"e", "a7"},
+		expectedCursor: &TxCursor{BlockHeight: 7, TxID: "a7"},
+	}, {
+		name:           "unmined cursor",
+		cursor:         &TxCursor{BlockHeight: -1, TxID: "u1"},
+		limit:          2,
+		expectedIDs:    []string{"u2", "a"},
+		expectedCursor: &TxCursor{BlockHeight: 0, TxID: "a"},
+	}, {
+		name:           "equal heights",
+		cursor:         &TxCursor{BlockHeight: 5, TxID: "b"},
+		limit:          2,
+		expectedIDs:    []string{"c", "d"},
+		expectedCursor: &TxCursor{BlockHeight: 5, TxID: "d"},
+	}, {
+		name:           "equal heights reversed",
+		cursor:         &TxCursor{BlockHeight: 5, TxID: "d"},
+		limit:          2,
+		reversed:       true,
+		expectedIDs:    []string{"c", "b"},
+		expectedCursor: &TxCursor{BlockHeight: 5, TxID: "b"},
+	}, {
+		name:        "cursor between txs",
+		cursor:      &TxCursor{BlockHeight: 5, TxID: "bb"},
+		limit:       10,
+		expectedIDs: []string{"c", "d", "a7", "e"},
+	}, {
+		name:        "cursor between txs reversed",
+		cursor:      &TxCursor{BlockHeight: 5, TxID: "bb"},
+		limit:       10,
+		reversed:    true,
+		expectedIDs: []string{"b", "a", "u2", "u1"},
+	}, {
+		name:        "last page",
+		cursor:      &TxCursor{BlockHeight: 7, TxID: "a7"},
+		limit:       3,
+		expectedIDs: []string{"e"},
+	}, {
+		name:        "last page reversed",
+		cursor:      &TxCursor{BlockHeight: -1, TxID: "u2"},
+		limit:       3,
+		reversed:    true,
+		expectedIDs: []string{"u1"},
+	}, {
+		name:        "after the last tx",
+		cursor:      &TxCursor{BlockHeight: 300, TxID: "e"},
+		limit:       3,
+		expectedIDs: nil,
+	}, {
+		name:        "no limit",
+		cursor:      &TxCursor{BlockHeight: 5, TxID: "d"},
+		expectedIDs: []string{"a7", "e"},
+	}, {
+		name:           "matchers",
+		limit:          2,
+		matchers:       []q.Matcher{q.Eq("Height", int32(5))},
+		expectedIDs:    []string{"b", "c"},
+		expectedCursor: &TxCursor{BlockHeight: 5, TxID: "c"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txs, cursor, err := db.FindTransactionsAfter(test.cursor, test.limit, test.reversed, test.matchers...)
+			if err != nil {
+				t.Fatalf("error finding txs: %v", err)
+			}
+			if ids := txIDs(txs); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Fatalf("expected txs %v, got %v", test.expectedIDs, ids)
+			}
+			if !reflect.DeepEqual(cursor, test.expectedCursor) {
+				t.Fatalf("expected cursor %+v, got %+v", test.expectedCursor, cursor)
+			}
+		})
+	}
+
+	// Paging with the returned cursors visits every tx once, in order.
+	for _, reversed := range []bool{false, true} {
+		var paged []string
+		var cursor *TxCursor
+		for page := 0; ; page++ {
+			if page > len(orderedTxs) {
+				t.Fatalf("paging did not end")
+			}
+			var txs []*cursorTestTx
+			var err error
+			txs, cursor, err = db.FindTransactionsAfter(cursor, 3, reversed)
+			if err != nil {
+				t.Fatalf("error finding txs: %v", err)
+			}
+			paged = append(paged, txIDs(txs)...)
+			if cursor == nil {
+				break
+			}
+		}
+		expectedIDs := txIDs(orderedTxs)
+		if reversed {
+			for i, j := 0, len(expectedIDs)-1; i < j; i, j = i+1, j-1 {
+				expectedIDs[i], expectedIDs[j] = expectedIDs[j], expectedIDs[i]
+			}
+		}
+		if !reflect.DeepEqual(paged, expectedIDs) {
+			t.Fatalf("expected paging reversed=%v to return %v, got %v", reversed, expectedIDs, paged)
+		}
+	}
+}
+
+func TestFindTransactionsAfterEmptyDB(t *testing.T) {
+	db := newCursorTestDB(t, nil)
+	txs, cursor, err := db.FindTransactionsAfter(nil, 10, false)
+	if err != nil {
+		t.Fatalf("error finding txs: %v", err)
+	}
+	if len(txs) != 0 || cursor != nil {
+		t.Fatalf("expected no txs and no cursor, got %d txs and cursor %+v", len(txs), cursor)
+	}
+}
+
+func TestTxIterator(t *testing.T) {
+	var txs []*cursorTestTx
+	for i := 0; i < 10; i++ {
+		height := int32(i / 3)
+		if i < 2 {
+			height = -1
+		}
+		txs = append(txs, &cursorTestTx{ID: fmt.Sprintf("tx%02d", i), Height: height})
+	}
+	db := newCursorTestDB(t, txs)
+
+	ascending := txIDs(txs)
+	descending := make([]string, len(ascending))
+	for i, id := range ascending {
+		descending[len(ascending)-1-i] = id
+	}
+
+	tests := []struct {
+		name        string
+		reversed    bool
+		batchSize   int
+		matchers    []q.Matcher
+		expectedIDs []string
+	}{
+		{"batch size 1", false, 1, nil, ascending},
+		{"batch size 3", false, 3, nil, ascending},
+		{"batch size divides txs", false, 5, nil, ascending},
+		{"default batch size", false, 0, nil, ascending},
+		{"reversed", true, 3, nil, descending},
+		{"unmined", false, 1, []q.Matcher{q.Lt("Height", int32(0))}, ascending[:2]},
+		{"no matches", false, 3, []q.Matcher{q.Gt("Height", int32(100))}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			it := db.IterateTransactions(test.reversed, test.batchSize, test.matchers...)
+			for it.Next() {
+				ids = append(ids, it.Tx().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("error iterating txs: %v", err)
+			}
+			if !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Fatalf("expected txs %v, got %v", test.expectedIDs, ids)
+			}
+			if it.Next() {
+				t.Fatalf("iterator advanced after the last tx")
+			}
+		})
+	}
+}
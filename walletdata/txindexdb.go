@@ -6,6 +6,7 @@ import (
 
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
+	"github.com/decred/slog"
 )
 
 var ErrTxIndexNotSupported = fmt.Errorf("database isn't configured for tx indexing")
@@ -18,6 +19,7 @@ type TxIndexDBConfig[Tx any] struct {
 	txBlockHeightField string
 	makeEmptyTx        func() *Tx
 	txUpdateHook       func(oldTx, newTx *Tx) (*Tx, error)
+	log                slog.Logger
 }
 
 // NewTxIndexDBConfig creates a TxIndexDBConfig.
@@ -28,9 +30,17 @@ func NewTxIndexDBConfig[Tx any](txVersion uint32, txIDField, txBlockHeightField
 		txBlockHeightField: txBlockHeightField,
 		makeEmptyTx:        makeEmptyTx,
 		txUpdateHook:       txUpdateHook,
+		log:                slog.Disabled,
 	}
 }
 
+// WithLogger sets the logger used to warn about tx lookups that use unindexed
+// fields and returns cfg.
+func (cfg *TxIndexDBConfig[Tx]) WithLogger(log slog.Logger) *TxIndexDBConfig[Tx] {
+	cfg.log = log
+	return cfg
+}
+
 type SORT struct {
 	fieldName string
 	reversed  bool
@@ -91,10 +101,19 @@ func (db *DB[Tx]) RollbackTxIndexLastBlock(height int32) error {
 	sampleTx := db.txIndexCfg.makeEmptyTx()
 	if height <= 0 {
 		err = batchTx.Drop(sampleTx)
+		if err == nil {
+			err = dropTxCursorIndex(batchTx)
+		}
 	} else {
-		err = batchTx.Select(q.Gt(db.txIndexCfg.txBlockHeightField, height)).Delete(sampleTx)
+		query := batchTx.Select(q.Gt(db.txIndexCfg.txBlockHeightField, height))
+		err = query.Each(sampleTx, func(record interface{}) error {
+			return db.deleteTxCursor(batchTx, record.(*Tx))
+		})
+		if err == nil {
+			err = query.Delete(sampleTx)
+		}
 	}
-	if err != nil {
+	if err != nil && err != storm.ErrNotFound {
 		return fmt.Errorf("error deleting invalidated wallet transactions: %s", err.Error())
 	}
 
@@ -147,6 +166,9 @@ func (db *DB[Tx]) IndexTransaction(tx *Tx) (bool, error) {
 	if err = batchTx.Save(tx); err != nil {
 		return false, err
 	}
+	if err = db.indexTxCursor(batchTx, tx, oldTx); err != nil {
+		return false, fmt.Errorf("error indexing tx cursor: %v", err)
+	}
 
 	if err = batchTx.Commit(); err != nil {
 		return false, fmt.Errorf("database error: %v", err)
@@ -163,6 +185,8 @@ func (db *DB[Tx]) FindTransaction(fieldName string, fieldValue interface{}) (*Tx
 		return nil, ErrTxIndexNotSupported
 	}
 
+	db.warnIfUnindexed(fieldName)
+
 	tx := db.txIndexCfg.makeEmptyTx()
 	err := db.db.One(fieldName, fieldValue, tx)
 	if err != nil {
@@ -175,7 +199,10 @@ func (db *DB[Tx]) FindTransaction(fieldName string, fieldValue interface{}) (*Tx
 // criteria and in the order specified by the sort argument. It is not an error
 // if no transaction is found to match the provided criteria, instead an empty
 // tx list and a nil error are returned. If no matcher is passed, all indexed
-// transactions will be returned.
+// transactions will be returned. Offset pagination is slow for large offsets
+// and pages shift if transactions are indexed between page reads, use
+// FindTransactionsAfter or IterateTransactions to page through many
+// transactions.
 func (db *DB[Tx]) FindTransactions(offset, limit int, sort *SORT, matchers ...q.Matcher) ([]*Tx, error) {
 	if db.txIndexCfg == nil {
 		return nil, ErrTxIndexNotSupported
@@ -214,6 +241,22 @@ func (db *DB[Tx]) CountTransactions(matchers ...q.Matcher) (int, error) {
 	return db.db.Select(matchers...).Count(sampleTx)
 }
 
+// warnIfUnindexed logs a warning the first time that a tx lookup is performed
+// using fieldName if fieldName is not an indexed tx field. Lookups that use
+// unindexed fields scan all indexed transactions.
+func (db *DB[Tx]) warnIfUnindexed(fieldName string) {
+	if db.txSchema.isIndexed(fieldName) {
+		return
+	}
+
+	db.unindexedLookupsMtx.Lock()
+	defer db.unindexedLookupsMtx.Unlock()
+	if !db.unindexedLookups[fieldName] {
+		db.unindexedLookups[fieldName] = true
+		db.txIndexCfg.log.Warnf("Looking up transactions by unindexed field %s, consider adding a storm index tag", fieldName)
+	}
+}
+
 func ignoreStormNotFoundError(err error) error {
 	if err == storm.ErrNotFound {
 		return nil
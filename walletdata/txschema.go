@@ -0,0 +1,164 @@
+package walletdata
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/asdine/storm"
+	bolt "go.etcd.io/bbolt"
+)
+
+// stormIndexPrefix is the prefix of the names of the buckets in which storm
+// stores the indices of a type.
+const stormIndexPrefix = "__storm_index_"
+
+// txSchema describes the storm configuration of an indexed tx type.
+type txSchema struct {
+	// indexedFields maps the names of the tx fields that are indexed by storm,
+	// including the ID field, to their storm index tags, such as "id,unique"
+	// or "index".
+	indexedFields map[string]string
+}
+
+// isIndexed returns true if fieldName is an indexed tx field.
+func (s *txSchema) isIndexed(fieldName string) bool {
+	_, indexed := s.indexedFields[fieldName]
+	return indexed
+}
+
+// indices returns the indexed tx fields and their storm index tags as
+// "name:tags" strings, in alphabetical order. The indices must be rebuilt
+// when they change, because storm uses the same index bucket for the unique
+// and non-unique indices of a field, with incompatible layouts.
+func (s *txSchema) indices() []string {
+	indices := make([]string, 0, len(s.indexedFields))
+	for name, tags := range s.indexedFields {
+		indices = append(indices, name+":"+tags)
+	}
+	sort.Strings(indices)
+	return indices
+}
+
+// parseTxSchema uses reflection to read the storm tags of sampleTx's fields
+// and verifies that the tx ID and block height fields specified in cfg are
+// usable for tx indexing. The tx ID field must be the storm ID of the tx type
+// or have a `storm:"unique"` tag, and the block height field must be an
+// integer.
+func parseTxSchema[Tx any](sampleTx *Tx, cfg *TxIndexDBConfig[Tx]) (*txSchema, error) {
+	txType := reflect.TypeOf(sampleTx).Elem()
+	if txType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tx type %s is not a struct", txType)
+	}
+
+	var idField string
+	indexedFields := make(map[string]string)
+	for i := 0; i < txType.NumField(); i++ {
+		field := txType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var indexTags []string
+		for _, tag := range strings.Split(field.Tag.Get("storm"), ",") {
+			switch tag {
+			case "id":
+				idField = field.Name
+			case "unique", "index":
+			default:
+				continue
+			}
+			indexTags = append(indexTags, tag)
+		}
+		if len(indexTags) > 0 {
+			sort.Strings(indexTags)
+			indexedFields[field.Name] = strings.Join(indexTags, ",")
+		}
+	}
+	// storm uses a field named ID as the ID if no field has an id tag.
+	if idField == "" {
+		if field, ok := txType.FieldByName("ID"); ok && field.IsExported() {
+			idField = field.Name
+			if tags, ok := indexedFields[idField]; ok {
+				indexedFields[idField] = "id," + tags
+			} else {
+				indexedFields[idField] = "id"
+			}
+		}
+	}
+	if idField == "" {
+		return nil, fmt.Errorf("tx type %s has no storm ID field", txType)
+	}
+
+	schema := &txSchema{indexedFields: indexedFields}
+
+	txIDField, ok := txType.FieldByName(cfg.txIDField)
+	if !ok || !txIDField.IsExported() {
+		return nil, fmt.Errorf("tx ID field %s is not an exported field of %s", cfg.txIDField, txType)
+	}
+	if cfg.txIDField != idField && !strings.Contains(","+txIDField.Tag.Get("storm")+",", ",unique,") {
+		return nil, fmt.Errorf("tx ID field %s must be the storm ID of %s or have a `storm:\"unique\"` tag", cfg.txIDField, txType)
+	}
+
+	blockHeightField, ok := txType.FieldByName(cfg.txBlockHeightField)
+	if !ok || !blockHeightField.IsExported() {
+		return nil, fmt.Errorf("tx block height field %s is not an exported field of %s", cfg.txBlockHeightField, txType)
+	}
+	switch blockHeightField.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return nil, fmt.Errorf("tx block height field %s must be a signed integer, not %s", cfg.txBlockHeightField, blockHeightField.Type)
+	}
+
+	return schema, nil
+}
+
+// rebuildTxIndices deletes and rebuilds the storm indices of the indexed
+// transactions in a single pass over them. storm's ReIndex is not used because
+// it reads the transactions one at a time with increasing offsets, which takes
+// time quadratic in the number of indexed transactions.
+func rebuildTxIndices[Tx any](db *storm.DB, txIndexCfg *TxIndexDBConfig[Tx]) error {
+	bucketName := reflect.TypeOf(txIndexCfg.makeEmptyTx()).Elem().Name()
+	return db.Bolt.Update(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		// Nested buckets hold the indices and storm's metadata, other keys
+		// are the IDs of the transactions.
+		var indexBuckets, txIDs [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			key := append([]byte(nil), k...)
+			if v != nil {
+				txIDs = append(txIDs, key)
+			} else if bytes.HasPrefix(k, []byte(stormIndexPrefix)) {
+				indexBuckets = append(indexBuckets, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range indexBuckets {
+			if err = bucket.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		// Saving the transactions again indexes them.
+		node := db.WithTransaction(btx)
+		for _, txID := range txIDs {
+			tx := txIndexCfg.makeEmptyTx()
+			if err = db.Codec().Unmarshal(bucket.Get(txID), tx); err != nil {
+				return fmt.Errorf("error decoding tx %x: %w", txID, err)
+			}
+			if err = node.Save(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,372 @@
+package walletdata
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asdine/storm/q"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestParseTxSchema(t *testing.T) {
+	type validTx struct {
+		Hash    string `storm:"id,unique"`
+		Height  int32  `storm:"index"`
+		Account uint32 `storm:"unique"`
+		Fee     int64
+		memo    string `storm:"index"`
+	}
+	type implicitIDTx struct {
+		ID     uint64
+		Height int64 `storm:"index"`
+	}
+	type uniqueTxIDTx struct {
+		Key    int    `storm:"id"`
+		Hash   string `storm:"unique"`
+		Height int
+	}
+	type indexedTxIDTx struct {
+		Key    int    `storm:"id"`
+		Hash   string `storm:"index"`
+		Height int32
+	}
+	type untaggedTxIDTx struct {
+		Key    int `storm:"id"`
+		Hash   string
+		Height int32
+	}
+	type unsignedHeightTx struct {
+		Hash   string `storm:"id"`
+		Height uint32
+	}
+	type stringHeightTx struct {
+		Hash   string `storm:"id"`
+		Height string
+	}
+	type noIDTx struct {
+		Hash   string `storm:"unique"`
+		Height int32
+	}
+
+	tests := []struct {
+		name string
+		// parse parses the schema of a tx type with the Hash or ID field as
+		// the tx ID field and the Height field as the block height field.
+		parse           func() (*txSchema, error)
+		expectedIndices []string
+		expectedErr     string
+	}{{
+		name: "valid",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&validTx{}, NewTxIndexDBConfig[validTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedIndices: []string{"Account:unique", "Hash:id,unique", "Height:index"},
+	}, {
+		name: "implicit ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&implicitIDTx{}, NewTxIndexDBConfig[implicitIDTx](1, "ID", "Height", nil, nil))
+		},
+		expectedIndices: []string{"Height:index", "ID:id"},
+	}, {
+		name: "unique tx ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&uniqueTxIDTx{}, NewTxIndexDBConfig[uniqueTxIDTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedIndices: []string{"Hash:unique", "Key:id"},
+	}, {
+		name: "non-unique index tx ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&indexedTxIDTx{}, NewTxIndexDBConfig[indexedTxIDTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "tx ID field Hash must be the storm ID",
+	}, {
+		name: "unindexed tx ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&untaggedTxIDTx{}, NewTxIndexDBConfig[untaggedTxIDTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "tx ID field Hash must be the storm ID",
+	}, {
+		name: "missing tx ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&validTx{}, NewTxIndexDBConfig[validTx](1, "TxID", "Height", nil, nil))
+		},
+		expectedErr: "tx ID field TxID is not an exported field",
+	}, {
+		name: "unsigned block height field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&unsignedHeightTx{}, NewTxIndexDBConfig[unsignedHeightTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "tx block height field Height must be a signed integer",
+	}, {
+		name: "string block height field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&stringHeightTx{}, NewTxIndexDBConfig[stringHeightTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "tx block height field Height must be a signed integer",
+	}, {
+		name: "unexported block height field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&validTx{}, NewTxIndexDBConfig[validTx](1, "Hash", "memo", nil, nil))
+		},
+		expectedErr: "tx block height field memo is not an exported field",
+	}, {
+		name: "no storm ID field",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(&noIDTx{}, NewTxIndexDBConfig[noIDTx](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "has no storm ID field",
+	}, {
+		name: "not a struct",
+		parse: func() (*txSchema, error) {
+			return parseTxSchema(new(string), NewTxIndexDBConfig[string](1, "Hash", "Height", nil, nil))
+		},
+		expectedErr: "is not a struct",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := test.parse()
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if indices := schema.indices(); !reflect.DeepEqual(indices, test.expectedIndices) {
+				t.Fatalf("expected indices %v, got %v", test.expectedIndices, indices)
+			}
+		})
+	}
+}
+
+// The schemaTx types below are the same tx type before and after a change of
+// its storm tags. They are declared in separate functions so that they have
+// the same name, which storm uses as the name of the tx bucket.
+
+// indexSchemaV1Txs indexes txs with Height and Account indexed and Fee not
+// indexed in a new database at dbPath.
+func indexSchemaV1Txs(t *testing.T, dbPath string, count int) {
+	t.Helper()
+	type schemaTx struct {
+		ID      string `storm:"id"`
+		Height  int32  `storm:"index"`
+		Account uint32 `storm:"index"`
+		Fee     int64
+	}
+	db := openTestDB(t, dbPath, NewTxIndexDBConfig(1, "ID", "Height", func() *schemaTx {
+		return &schemaTx{}
+	}, nil))
+	for i := 0; i < count; i++ {
+		// storm does not index zero values.
+		tx := &schemaTx{ID: string(rune('a' + i)), Height: int32(10*i) - 1, Account: uint32(i + 1), Fee: int64(i%2) + 1}
+		if _, err := db.IndexTransaction(tx); err != nil {
+			t.Fatalf("error indexing tx: %v", err)
+		}
+	}
+	if err := db.db.Close(); err != nil {
+		t.Fatalf("error closing db: %v", err)
+	}
+}
+
+// openSchemaV2DB opens the database at dbPath with Height no longer indexed,
+// Account indexed as unique and Fee indexed. Returns the tx ID of the tx with
+// the specified account and the number of txs with a fee of 2, looked up using
+// the new indices.
+func openSchemaV2DB(t *testing.T, dbPath string, account uint32) (string, int) {
+	t.Helper()
+	type schemaTx struct {
+		ID      string `storm:"id"`
+		Height  int32
+		Account uint32 `storm:"unique"`
+		Fee     int64  `storm:"index"`
+	}
+	db := openTestDB(t, dbPath, NewTxIndexDBConfig(1, "ID", "Height", func() *schemaTx {
+		return &schemaTx{}
+	}, nil))
+	defer db.db.Close()
+
+	tx, err := db.FindTransaction("Account", account)
+	if err != nil || tx == nil {
+		t.Fatalf("error finding tx by account %d: %v", account, err)
+	}
+	var txs []*schemaTx
+	if err := db.db.Find("Fee", int64(2), &txs); err != nil {
+		t.Fatalf("error finding txs by fee: %v", err)
+	}
+	return tx.ID, len(txs)
+}
+
+// readIndexBuckets returns the number of entries in the storm index buckets of
+// the schemaTx bucket, and whether each index is a list index, which has a
+// nested bucket of IDs, rather than a unique index.
+func readIndexBuckets(t *testing.T, dbPath string) (entries map[string]int, isList map[string]bool) {
+	t.Helper()
+	db, err := bolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		t.Fatalf("error opening db: %v", err)
+	}
+	defer db.Close()
+
+	entries = make(map[string]int)
+	isList = make(map[string]bool)
+	err = db.View(func(btx *bolt.Tx) error {
+		return btx.Bucket([]byte("schemaTx")).ForEach(func(k, v []byte) error {
+			name := string(k)
+			if v != nil || !strings.HasPrefix(name, stormIndexPrefix) {
+				return nil
+			}
+			name = strings.TrimPrefix(name, stormIndexPrefix)
+			return btx.Bucket([]byte("schemaTx")).Bucket(k).ForEach(func(k, v []byte) error {
+				if v == nil {
+					isList[name] = true
+				} else {
+					entries[name]++
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		t.Fatalf("error reading index buckets: %v", err)
+	}
+	return entries, isList
+}
+
+// countTxCursors returns the number of entries in the tx cursor index.
+func countTxCursors(t *testing.T, dbPath string) int {
+	t.Helper()
+	db, err := bolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		t.Fatalf("error opening db: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	err = db.View(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket([]byte(txCursorBktName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			if v != nil {
+				count++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("error reading tx cursor index: %v", err)
+	}
+	return count
+}
+
+func TestRebuildTxIndicesOnTagChange(t *testing.T) {
+	const txCount = 5
+	dbPath := filepath.Join(t.TempDir(), "walletdata.db")
+	indexSchemaV1Txs(t, dbPath, txCount)
+
+	entries, isList := readIndexBuckets(t, dbPath)
+	expectedLists := map[string]bool{"Height": true, "Account": true}
+	if !reflect.DeepEqual(isList, expectedLists) {
+		t.Fatalf("expected list indices %v before the tag change, got %v", expectedLists, isList)
+	}
+	if entries["Height"] != txCount || entries["Account"] != txCount {
+		t.Fatalf("expected %d Height and Account index entries before the tag change, got %v", txCount, entries)
+	}
+
+	txID, feeTxs := openSchemaV2DB(t, dbPath, 4)
+	if txID != "d" {
+		t.Fatalf("expected tx d for account 4, got %s", txID)
+	}
+	if feeTxs != txCount/2 {
+		t.Fatalf("expected %d txs with a fee of 2, got %d", txCount/2, feeTxs)
+	}
+
+	// Height is no longer indexed, Account is a unique index with an entry
+	// per tx and Fee is a list index.
+	entries, isList = readIndexBuckets(t, dbPath)
+	if _, found := entries["Height"]; found || isList["Height"] {
+		t.Fatalf("Height index was not deleted")
+	}
+	if isList["Account"] || entries["Account"] != txCount {
+		t.Fatalf("expected a unique Account index with %d entries, got list=%v with %d entries", txCount, isList["Account"], entries["Account"])
+	}
+	if !isList["Fee"] || entries["Fee"] != txCount {
+		t.Fatalf("expected a list Fee index with %d entries, got list=%v with %d entries", txCount, isList["Fee"], entries["Fee"])
+	}
+	if n := countTxCursors(t, dbPath); n != txCount {
+		t.Fatalf("expected %d tx cursors, got %d", txCount, n)
+	}
+}
+
+func TestRebuildTxIndicesOfOldDB(t *testing.T) {
+	const txCount = 5
+	dbPath := filepath.Join(t.TempDir(), "walletdata.db")
+	indexSchemaV1Txs(t, dbPath, txCount)
+
+	// Databases created before the indexed fields were saved and before the
+	// tx cursor index was added have neither.
+	db, err := bolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		t.Fatalf("error opening db: %v", err)
+	}
+	err = db.Update(func(btx *bolt.Tx) error {
+		if err := btx.DeleteBucket([]byte(txCursorBktName)); err != nil {
+			return err
+		}
+		return btx.Bucket([]byte(metadataBktName)).Delete([]byte(txIndexedFieldsKey))
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("error reverting db to the old schema: %v", err)
+	}
+
+	type schemaTx struct {
+		ID      string `storm:"id"`
+		Height  int32  `storm:"index"`
+		Account uint32 `storm:"index"`
+		Fee     int64
+	}
+	cfg := NewTxIndexDBConfig(1, "ID", "Height", func() *schemaTx {
+		return &schemaTx{}
+	}, nil)
+	txDB := openTestDB(t, dbPath, cfg)
+
+	var indices []string
+	if err := txDB.db.Get(metadataBktName, txIndexedFieldsKey, &indices); err != nil {
+		t.Fatalf("error reading indexed fields: %v", err)
+	}
+	if !reflect.DeepEqual(indices, txDB.txSchema.indices()) {
+		t.Fatalf("expected indexed fields %v, got %v", txDB.txSchema.indices(), indices)
+	}
+
+	var heights []int32
+	it := txDB.IterateTransactions(false, 2)
+	for it.Next() {
+		heights = append(heights, it.Tx().Height)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("error iterating txs: %v", err)
+	}
+	if expected := []int32{-1, 9, 19, 29, 39}; !reflect.DeepEqual(heights, expected) {
+		t.Fatalf("expected tx heights %v from the rebuilt tx cursor index, got %v", expected, heights)
+	}
+	txs, err := txDB.FindTransactions(0, 0, nil, q.Eq("Account", uint32(3)))
+	if err != nil || len(txs) != 1 || txs[0].ID != "c" {
+		t.Fatalf("expected tx c for account 3, got %v, %v", txs, err)
+	}
+	txDB.db.Close()
+
+	entries, isList := readIndexBuckets(t, dbPath)
+	if !isList["Height"] || !isList["Account"] || entries["Height"] != txCount || entries["Account"] != txCount {
+		t.Fatalf("expected list Height and Account indices with %d entries, got lists %v with entries %v", txCount, isList, entries)
+	}
+	if n := countTxCursors(t, dbPath); n != txCount {
+		t.Fatalf("expected %d tx cursors, got %d", txCount, n)
+	}
+}